@@ -0,0 +1,86 @@
+// Apicheck compares the exported API of a set of packages against a
+// checked-in snapshot and classifies every change as compatible or breaking.
+//
+// Usage:
+//
+//	apicheck [-snapshot file] [-update] [dir | dir/...]...
+//
+// By default the packages under language/decoupling/packages are checked
+// against language/decoupling/packages/api.txt. With -update the snapshot
+// is rewritten from the current tree instead. The exit status is 1 when a
+// breaking change is found.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/apicheck"
+)
+
+func main() {
+	snapshot := flag.String("snapshot", "language/decoupling/packages/api.txt", "path of the snapshot file")
+	update := flag.Bool("update", false, "rewrite the snapshot from the current tree")
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./language/decoupling/packages/..."}
+	}
+
+	breaking, err := run(*snapshot, *update, patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "apicheck:", err)
+		os.Exit(2)
+	}
+
+	if breaking {
+		os.Exit(1)
+	}
+}
+
+// run performs the update or the comparison and reports whether a breaking
+// change was found.
+func run(snapshot string, update bool, patterns []string) (bool, error) {
+	pkgs, err := analysis.Load(patterns...)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := apicheck.Snapshot(pkgs)
+	if err != nil {
+		return false, err
+	}
+
+	if update {
+		var buf bytes.Buffer
+		if err := apicheck.WriteSnapshot(&buf, current); err != nil {
+			return false, err
+		}
+		return false, os.WriteFile(snapshot, buf.Bytes(), 0644)
+	}
+
+	f, err := os.Open(snapshot)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	recorded, err := apicheck.ReadSnapshot(f)
+	if err != nil {
+		return false, err
+	}
+
+	var breaking bool
+	for _, c := range apicheck.Compare(recorded, current) {
+		fmt.Println(c)
+		if c.Breaking {
+			breaking = true
+		}
+	}
+
+	return breaking, nil
+}
@@ -0,0 +1,127 @@
+// Appendgrowth records how append grows the capacity of a slice for
+// different element sizes and compares it with the size class model.
+//
+// Usage:
+//
+//	appendgrowth [-n 100000] [-sizes 1,8,16,24,64] [-pointers both] [-csv]
+//
+// Every row is one growth of the backing array with the capacity before and
+// after, the percent growth and the capacity the model predicts.
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"ultimate-go-programming/tools/growth"
+)
+
+// config is a single element layout to measure.
+type config struct {
+	size        uintptr
+	hasPointers bool
+}
+
+func main() {
+	n := flag.Int("n", 1e5, "number of elements to append")
+	sizes := flag.String("sizes", "1,8,16,24,64", "comma separated list of element sizes in bytes")
+	pointers := flag.String("pointers", "both", "element layouts to measure: no, yes or both")
+	asCSV := flag.Bool("csv", false, "write CSV instead of a table")
+	flag.Parse()
+
+	configs, err := parseConfigs(*sizes, *pointers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "appendgrowth:", err)
+		os.Exit(2)
+	}
+
+	if err := run(os.Stdout, *n, configs, *asCSV); err != nil {
+		fmt.Fprintln(os.Stderr, "appendgrowth:", err)
+		os.Exit(1)
+	}
+}
+
+// run measures every configuration and writes the results.
+func run(w io.Writer, n int, configs []config, asCSV bool) error {
+	header := []string{"size", "pointers", "len", "oldcap", "cap", "growth%", "bytes", "modelcap", "match"}
+
+	var rows [][]string
+	for _, c := range configs {
+		list, err := growth.Compare(n, c.size, c.hasPointers)
+		if err != nil {
+			return err
+		}
+
+		for _, cmp := range list {
+			rows = append(rows, []string{
+				strconv.Itoa(int(c.size)),
+				strconv.FormatBool(c.hasPointers),
+				strconv.Itoa(cmp.Measured.Len),
+				strconv.Itoa(cmp.Measured.OldCap),
+				strconv.Itoa(cmp.Measured.Cap),
+				strconv.FormatFloat(cmp.Measured.Percent, 'f', 1, 64),
+				strconv.Itoa(int(cmp.Measured.Bytes)),
+				strconv.Itoa(cmp.Model.Cap),
+				strconv.FormatBool(cmp.Match),
+			})
+		}
+	}
+
+	if asCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		return cw.WriteAll(rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+// parseConfigs converts the flag values into element layouts. Layouts with
+// pointers that can't exist, like a 1 byte element, are skipped when both
+// layouts are requested.
+func parseConfigs(sizes string, pointers string) ([]config, error) {
+	var layouts []bool
+	switch pointers {
+	case "no":
+		layouts = []bool{false}
+	case "yes":
+		layouts = []bool{true}
+	case "both":
+		layouts = []bool{false, true}
+	default:
+		return nil, fmt.Errorf("unknown pointers value %q", pointers)
+	}
+
+	var configs []config
+	for _, field := range strings.Split(sizes, ",") {
+		size, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", field, err)
+		}
+
+		for _, hasPointers := range layouts {
+			if _, err := growth.ElemType(uintptr(size), hasPointers); err != nil {
+				if len(layouts) > 1 {
+					continue
+				}
+				return nil, err
+			}
+			configs = append(configs, config{size: uintptr(size), hasPointers: hasPointers})
+		}
+	}
+
+	return configs, nil
+}
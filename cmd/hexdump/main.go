@@ -0,0 +1,130 @@
+// Hexdump writes a file or a string as offset, hex and ASCII columns with
+// annotations underneath: rune boundaries, the unused tail of a buffer and
+// labeled byte ranges. It can also compare two inputs side by side.
+//
+// Usage:
+//
+//	hexdump [-w 16] [-runes] [-size n] [-label start:end:text]... [-s string | file]
+//	hexdump -diff [-w 8] a b
+//
+// With -size the input is copied into a buffer of n bytes the way retrieve
+// reads into make([]byte, 100), and the bytes past the input are marked as
+// unused. With no file and no -s the input is read from standard input.
+// With -diff the two arguments are files, or strings when -s is set.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"ultimate-go-programming/tools/hexdump"
+)
+
+// labels collects the repeated -label flags.
+type labels []hexdump.Label
+
+// String implements the flag.Value interface.
+func (l *labels) String() string {
+	list := make([]string, 0, len(*l))
+	for _, label := range *l {
+		list = append(list, fmt.Sprintf("%d:%d:%s", label.Start, label.End, label.Text))
+	}
+	return strings.Join(list, ",")
+}
+
+// Set implements the flag.Value interface.
+func (l *labels) Set(value string) error {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("label %q must be start:end:text", value)
+	}
+
+	start, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("label start: %w", err)
+	}
+	end, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("label end: %w", err)
+	}
+	if end <= start {
+		return fmt.Errorf("label %q ends before it starts", value)
+	}
+
+	*l = append(*l, hexdump.Label{Start: start, End: end, Text: parts[2]})
+	return nil
+}
+
+func main() {
+	var ls labels
+	width := flag.Int("w", 0, "bytes per line (default 16, or 8 with -diff)")
+	runes := flag.Bool("runes", false, "mark rune boundaries and invalid UTF-8")
+	size := flag.Int("size", -1, "copy the input into a buffer of this size and mark the unused tail")
+	isString := flag.Bool("s", false, "arguments are strings instead of files")
+	diff := flag.Bool("diff", false, "compare two inputs side by side")
+	flag.Var(&ls, "label", "label a byte range as start:end:text, may be repeated")
+	flag.Parse()
+
+	if err := run(os.Stdout, flag.Args(), *isString, *diff, *width, *runes, *size, ls); err != nil {
+		fmt.Fprintln(os.Stderr, "hexdump:", err)
+		os.Exit(1)
+	}
+}
+
+// run reads the inputs and writes the dump or the diff.
+func run(w io.Writer, args []string, isString bool, diff bool, width int, runes bool, size int, ls labels) error {
+	if diff {
+		if len(args) != 2 {
+			return fmt.Errorf("-diff needs two inputs")
+		}
+
+		a, err := input(args[0], isString)
+		if err != nil {
+			return err
+		}
+		b, err := input(args[1], isString)
+		if err != nil {
+			return err
+		}
+
+		return hexdump.Diff(w, a, b, width)
+	}
+
+	var data []byte
+	var err error
+	switch len(args) {
+	case 0:
+		data, err = io.ReadAll(os.Stdin)
+	case 1:
+		data, err = input(args[0], isString)
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if err != nil {
+		return err
+	}
+
+	d := hexdump.New(data)
+	if size >= 0 {
+		buf := make([]byte, size)
+		d.Data = buf
+		d.Used = copy(buf, data)
+	}
+	d.Width = width
+	d.Runes = runes
+	d.Labels = ls
+
+	return d.Write(w)
+}
+
+// input returns the argument itself or the contents of the file it names.
+func input(arg string, isString bool) ([]byte, error) {
+	if isString {
+		return []byte(arg), nil
+	}
+	return os.ReadFile(arg)
+}
@@ -0,0 +1,50 @@
+// Ifacereport lists every interface declared in a set of packages with its
+// implementers, call sites and the interface pollution smells it exhibits.
+//
+// Usage:
+//
+//	ifacereport [-json] [dir | dir/...]...
+//
+// With no arguments the packages below the current directory are reported.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/ifacereport"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "write the report as JSON")
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	pkgs, err := analysis.Load(patterns...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ifacereport:", err)
+		os.Exit(2)
+	}
+
+	r := ifacereport.Build(pkgs)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(r)
+	} else {
+		err = r.WriteText(os.Stdout)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ifacereport:", err)
+		os.Exit(1)
+	}
+}
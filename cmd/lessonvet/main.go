@@ -0,0 +1,123 @@
+// Lessonvet runs the course analyzers against a set of packages.
+//
+// Usage:
+//
+//	lessonvet [-run name,...] [-min severity] [dir | dir/...]...
+//
+// With no arguments the packages below the current directory are checked.
+// The exit status is 1 when any diagnostic at or above the minimum severity
+// is reported.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/analysis/exposed"
+	"ultimate-go-programming/tools/analysis/prealloc"
+	"ultimate-go-programming/tools/analysis/shadow"
+)
+
+// analyzers is the set of checks lessonvet knows how to run.
+var analyzers = []*analysis.Analyzer{
+	exposed.Analyzer,
+	prealloc.Analyzer,
+	shadow.Analyzer,
+}
+
+func main() {
+	run := flag.String("run", "", "comma separated list of analyzers to run (default all)")
+	minSeverity := flag.String("min", "info", "minimum severity to report: info, warning or error")
+	list := flag.Bool("list", false, "list the available analyzers and exit")
+	flag.Parse()
+
+	if *list {
+		for _, a := range analyzers {
+			fmt.Printf("%-20s %s\n", a.Name, a.Doc)
+		}
+		return
+	}
+
+	selected, err := selectAnalyzers(*run)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lessonvet:", err)
+		os.Exit(2)
+	}
+
+	threshold, err := parseSeverity(*minSeverity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lessonvet:", err)
+		os.Exit(2)
+	}
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	pkgs, err := analysis.Load(patterns...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lessonvet:", err)
+		os.Exit(2)
+	}
+
+	diags, err := analysis.Run(pkgs, selected...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lessonvet:", err)
+		os.Exit(2)
+	}
+
+	var reported int
+	for _, d := range diags {
+		if d.Severity < threshold {
+			continue
+		}
+		fmt.Println(d)
+		reported++
+	}
+
+	if reported > 0 {
+		os.Exit(1)
+	}
+}
+
+// selectAnalyzers returns the analyzers named in the comma separated list.
+func selectAnalyzers(names string) ([]*analysis.Analyzer, error) {
+	if names == "" {
+		return analyzers, nil
+	}
+
+	var selected []*analysis.Analyzer
+	for _, name := range strings.Split(names, ",") {
+		a := findAnalyzer(strings.TrimSpace(name))
+		if a == nil {
+			return nil, fmt.Errorf("unknown analyzer %q", name)
+		}
+		selected = append(selected, a)
+	}
+
+	return selected, nil
+}
+
+// findAnalyzer looks up an analyzer by name.
+func findAnalyzer(name string) *analysis.Analyzer {
+	for _, a := range analyzers {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// parseSeverity converts the name of a severity into its value.
+func parseSeverity(name string) (analysis.Severity, error) {
+	for _, s := range []analysis.Severity{analysis.Info, analysis.Warning, analysis.Error} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", name)
+}
@@ -0,0 +1,97 @@
+// Methodsets prints a matrix showing which named types in a package, as
+// values and as pointers, satisfy which interfaces.
+//
+// Usage:
+//
+//	methodsets [-json] [-iface io.Reader,fmt.Stringer] [dir]
+//
+// With no directory the package in the current directory is used. The
+// -iface flag adds interfaces from other packages to the matrix.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"strings"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/methodset"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "write the matrix as JSON")
+	extra := flag.String("iface", "", "comma separated list of additional interfaces, like io.Reader")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, *extra, *asJSON); err != nil {
+		fmt.Fprintln(os.Stderr, "methodsets:", err)
+		os.Exit(1)
+	}
+}
+
+// run builds and writes the matrix for the package in dir.
+func run(dir string, extra string, asJSON bool) error {
+	l, err := analysis.NewLoader(dir)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := l.LoadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var ifaces []*types.Named
+	if extra != "" {
+		for _, name := range strings.Split(extra, ",") {
+			iface, err := lookupInterface(l, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			ifaces = append(ifaces, iface)
+		}
+	}
+
+	m := methodset.Build(pkg.Types, ifaces...)
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	}
+
+	return m.WriteTable(os.Stdout)
+}
+
+// lookupInterface finds a named interface written as importpath.Name.
+func lookupInterface(l *analysis.Loader, name string) (*types.Named, error) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return nil, fmt.Errorf("interface %q must be written as importpath.Name", name)
+	}
+
+	pkg, err := l.Import(name[:i])
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := pkg.Scope().Lookup(name[i+1:]).(*types.TypeName)
+	if !ok || !types.IsInterface(obj.Type()) {
+		return nil, fmt.Errorf("%s is not an interface", name)
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a named interface", name)
+	}
+
+	return named, nil
+}
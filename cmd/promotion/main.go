@@ -0,0 +1,91 @@
+// Promotion lists every field and method reachable through embedding for
+// struct types in a package, showing which members are promoted, which are
+// shadowed by outer declarations and which are ambiguous.
+//
+// Usage:
+//
+//	promotion [-json] dir [Type...]
+//
+// With no type names every struct type declared in the package is explored.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/promotion"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "write the reports as JSON")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: promotion [-json] dir [Type...]")
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Args()[1:], *asJSON); err != nil {
+		fmt.Fprintln(os.Stderr, "promotion:", err)
+		os.Exit(1)
+	}
+}
+
+// run explores the named types from the package in dir.
+func run(dir string, names []string, asJSON bool) error {
+	pkgs, err := analysis.Load(dir)
+	if err != nil {
+		return err
+	}
+	scope := pkgs[0].Types.Scope()
+
+	if len(names) == 0 {
+		for _, name := range scope.Names() {
+			if tn, ok := scope.Lookup(name).(*types.TypeName); ok && !tn.IsAlias() {
+				if _, ok := tn.Type().Underlying().(*types.Struct); ok {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	reports := make([]*promotion.Report, 0, len(names))
+	for _, name := range names {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			return fmt.Errorf("type %s not found in %s", name, pkgs[0].Path)
+		}
+
+		named, ok := tn.Type().(*types.Named)
+		if !ok {
+			return fmt.Errorf("%s is not a named type", name)
+		}
+
+		r, err := promotion.Explore(named)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, r)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	}
+
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := r.WriteTable(os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
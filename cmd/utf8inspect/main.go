@@ -0,0 +1,116 @@
+// Utf8inspect decodes strings as UTF-8 and prints every rune with its byte
+// offset, encoded bytes, code point and category. Invalid sequences are
+// reported with their position and combining marks are flagged.
+//
+// Usage:
+//
+//	utf8inspect [-x] [-json] [string...]
+//	utf8inspect [-x] -compare a b
+//
+// With no strings the bytes are read from standard input. With -x every
+// string is hex, like "c3a0" or "61 cc 80", so invalid bytes can be given.
+// With -compare the two strings are explained side by side one character
+// at a time.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ultimate-go-programming/tools/utf8inspect"
+)
+
+func main() {
+	isHex := flag.Bool("x", false, "strings are hex encoded bytes")
+	asJSON := flag.Bool("json", false, "write the runes as JSON")
+	compare := flag.Bool("compare", false, "compare two strings character by character")
+	flag.Parse()
+
+	inputs, err := readInputs(flag.Args(), *isHex)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "utf8inspect:", err)
+		os.Exit(2)
+	}
+
+	if *compare {
+		if len(inputs) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: utf8inspect [-x] -compare a b")
+			os.Exit(2)
+		}
+		if err := utf8inspect.WriteComparison(os.Stdout, inputs[0], inputs[1]); err != nil {
+			fmt.Fprintln(os.Stderr, "utf8inspect:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(os.Stdout, inputs, *asJSON); err != nil {
+		fmt.Fprintln(os.Stderr, "utf8inspect:", err)
+		os.Exit(1)
+	}
+}
+
+// readInputs returns the bytes of every argument, or of standard input
+// when there are no arguments.
+func readInputs(args []string, isHex bool) ([][]byte, error) {
+	if len(args) == 0 {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		if isHex {
+			args = []string{string(b)}
+		} else {
+			return [][]byte{b}, nil
+		}
+	}
+
+	inputs := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		if !isHex {
+			inputs = append(inputs, []byte(arg))
+			continue
+		}
+
+		b, err := hex.DecodeString(strings.Join(strings.Fields(arg), ""))
+		if err != nil {
+			return nil, fmt.Errorf("decoding %q: %w", arg, err)
+		}
+		inputs = append(inputs, b)
+	}
+
+	return inputs, nil
+}
+
+// run inspects every input and writes the runes.
+func run(w io.Writer, inputs [][]byte, asJSON bool) error {
+	if asJSON {
+		all := make([][]utf8inspect.Rune, len(inputs))
+		for i, in := range inputs {
+			all[i] = utf8inspect.Inspect(in)
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(all)
+	}
+
+	for i, in := range inputs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		runes := utf8inspect.Inspect(in)
+		fmt.Fprintf(w, "%q: %d bytes, %d runes\n", in, len(in), len(runes))
+		if err := utf8inspect.WriteTable(w, runes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package datastructures
+
+import "fmt"
+
+// UserColumns stores a collection of users as a struct of arrays. Every field
+// of sliceUser lives in its own slice and the same index in every slice
+// describes the same user.
+//
+// A loop that only reads likes walks through memory holding nothing but
+// likes, so every byte brought into the cache is used. With a []sliceUser the
+// same loop drags the id and the name header of every user into the cache
+// along with its likes.
+type UserColumns struct {
+	ids   []int
+	names []string
+	likes []int
+}
+
+// NewUserColumns constructs an empty UserColumns with room for capacity
+// users.
+func NewUserColumns(capacity int) *UserColumns {
+	c := UserColumns{
+		ids:   make([]int, 0, capacity),
+		names: make([]string, 0, capacity),
+		likes: make([]int, 0, capacity),
+	}
+	return &c
+}
+
+// toColumns converts a slice of users into columns.
+func toColumns(users []sliceUser) *UserColumns {
+	c := NewUserColumns(len(users))
+	for _, u := range users {
+		c.Append(u.id, u.name, u.likes)
+	}
+	return c
+}
+
+// users converts the columns back into a slice of users.
+func (c *UserColumns) users() []sliceUser {
+	users := make([]sliceUser, c.Len())
+	for i := range users {
+		users[i] = sliceUser{
+			id:    c.ids[i],
+			name:  c.names[i],
+			likes: c.likes[i],
+		}
+	}
+	return users
+}
+
+// Len returns the number of users.
+func (c *UserColumns) Len() int {
+	return len(c.ids)
+}
+
+// Append adds a user to the end of every column.
+func (c *UserColumns) Append(id int, name string, likes int) {
+	c.ids = append(c.ids, id)
+	c.names = append(c.names, name)
+	c.likes = append(c.likes, likes)
+}
+
+// FilterLikes returns the users with at least the specified number of likes.
+// The likes column is scanned first and the other columns are only read for
+// the users that match.
+func (c *UserColumns) FilterLikes(atLeast int) *UserColumns {
+	var matches UserColumns
+	for i, likes := range c.likes {
+		if likes >= atLeast {
+			matches.Append(c.ids[i], c.names[i], likes)
+		}
+	}
+	return &matches
+}
+
+// SumLikes returns the total number of likes across all users.
+func (c *UserColumns) SumLikes() int {
+	var total int
+	for _, likes := range c.likes {
+		total += likes
+	}
+	return total
+}
+
+// AddLikes adds n likes to every user.
+func (c *UserColumns) AddLikes(n int) {
+	for i := range c.likes {
+		c.likes[i] += n
+	}
+}
+
+// =============================================================================
+
+// sumLikes returns the total number of likes across all users.
+func sumLikes(users []sliceUser) int {
+	var total int
+	for i := range users {
+		total += users[i].likes
+	}
+	return total
+}
+
+// addLikes adds n likes to every user.
+func addLikes(users []sliceUser, n int) {
+	for i := range users {
+		users[i].likes += n
+	}
+}
+
+// SlicesExample10 is a sample program to show a collection of users stored as
+// a struct of slices. The benchmarks in columns_test.go run the same scan and
+// update over a slice of structs and over a struct of slices to show how the
+// layout of data in memory changes the cost of walking through it.
+func SlicesExample10() {
+	// Filtering only reads the names and ids of the users that match.
+	columns := toColumns([]sliceUser{
+		{1, "Bill", 12},
+		{2, "Joan", 48},
+		{3, "Lisa", 3},
+		{4, "Tom", 97},
+	})
+	popular := columns.FilterLikes(40)
+	fmt.Printf("%+v Total likes: %d\n", popular.users(), popular.SumLikes())
+
+	columns.AddLikes(1)
+	fmt.Println("Total likes after adding one to every user:", columns.SumLikes())
+}
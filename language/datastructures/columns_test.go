@@ -0,0 +1,110 @@
+package datastructures
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestUserColumns(t *testing.T) {
+	users := []sliceUser{
+		{1, "Bill", 12},
+		{2, "Joan", 48},
+		{3, "Lisa", 3},
+		{4, "Tom", 97},
+	}
+
+	c := toColumns(users)
+	if c.Len() != len(users) || !slices.Equal(c.users(), users) {
+		t.Fatalf("columns hold %v, want %v", c.users(), users)
+	}
+	if c.SumLikes() != sumLikes(users) {
+		t.Errorf("SumLikes = %d, want %d", c.SumLikes(), sumLikes(users))
+	}
+
+	popular := c.FilterLikes(40)
+	if want := []sliceUser{{2, "Joan", 48}, {4, "Tom", 97}}; !slices.Equal(popular.users(), want) {
+		t.Errorf("FilterLikes(40) = %v, want %v", popular.users(), want)
+	}
+	if none := c.FilterLikes(1000); none.Len() != 0 {
+		t.Errorf("FilterLikes(1000) = %v, want none", none.users())
+	}
+
+	c.AddLikes(2)
+	addLikes(users, 2)
+	if !slices.Equal(c.users(), users) {
+		t.Errorf("after AddLikes columns hold %v, want %v", c.users(), users)
+	}
+}
+
+// =============================================================================
+
+// The benchmarks run the same scan and update over a slice of structs and
+// over a struct of slices and report the time per user:
+//
+//	go test -run none -bench Likes
+//
+// While the users fit in the cache both layouts cost about the same. Once
+// they don't, the columns are faster since a scan of the likes only brings
+// likes into the cache.
+
+// benchSizes are the numbers of users to benchmark.
+var benchSizes = []int{1e3, 1e4, 1e5, 1e6, 1e7}
+
+// newUsers returns n users.
+func newUsers(n int) []sliceUser {
+	users := make([]sliceUser, n)
+	for i := range users {
+		users[i] = sliceUser{i, strconv.Itoa(i % 1000), i % 100}
+	}
+	return users
+}
+
+// perUser reports the time of every iteration divided by n.
+func perUser(b *testing.B, n int) {
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(n), "ns/user")
+}
+
+// likesSink keeps the compiler from dropping the sums.
+var likesSink int
+
+func BenchmarkSumLikes(b *testing.B) {
+	for _, n := range benchSizes {
+		users := newUsers(n)
+		columns := toColumns(users)
+
+		b.Run(fmt.Sprintf("structs/%d", n), func(b *testing.B) {
+			for range b.N {
+				likesSink = sumLikes(users)
+			}
+			perUser(b, n)
+		})
+		b.Run(fmt.Sprintf("columns/%d", n), func(b *testing.B) {
+			for range b.N {
+				likesSink = columns.SumLikes()
+			}
+			perUser(b, n)
+		})
+	}
+}
+
+func BenchmarkAddLikes(b *testing.B) {
+	for _, n := range benchSizes {
+		users := newUsers(n)
+		columns := toColumns(users)
+
+		b.Run(fmt.Sprintf("structs/%d", n), func(b *testing.B) {
+			for range b.N {
+				addLikes(users, 1)
+			}
+			perUser(b, n)
+		})
+		b.Run(fmt.Sprintf("columns/%d", n), func(b *testing.B) {
+			for range b.N {
+				columns.AddLikes(1)
+			}
+			perUser(b, n)
+		})
+	}
+}
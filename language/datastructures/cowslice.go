@@ -0,0 +1,153 @@
+package datastructures
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// cowStore is the bookkeeping shared by every COWSlice that points into the
+// same backing array.
+type cowStore struct {
+	views  int  // Number of COWSlice values using the backing array.
+	copies *int // Copies made by the family of slices this one came from.
+}
+
+// COWSlice is a slice that shares its backing array with the views taken
+// from it until one of them writes. The writer then gets a private copy of
+// its elements, so a change made through one view is never seen through
+// another and append never overwrites elements another view can see.
+//
+// A view that is no longer used still counts as sharing the backing array,
+// so a write can copy elements nobody else will read. The number of copies
+// is tracked so the cost of sharing can be measured. A COWSlice is not safe
+// for concurrent use.
+type COWSlice[T any] struct {
+	data  []T
+	store *cowStore
+}
+
+// NewCOWSlice constructs a COWSlice holding a copy of the values.
+func NewCOWSlice[T any](values ...T) *COWSlice[T] {
+	s := COWSlice[T]{
+		data: slices.Clone(values),
+		store: &cowStore{
+			views:  1,
+			copies: new(int),
+		},
+	}
+	return &s
+}
+
+// Len returns the number of elements in the slice.
+func (s *COWSlice[T]) Len() int {
+	return len(s.data)
+}
+
+// Get returns the element at index i.
+func (s *COWSlice[T]) Get(i int) T {
+	return s.data[i]
+}
+
+// Set replaces the element at index i, copying the elements first when the
+// backing array is shared.
+func (s *COWSlice[T]) Set(i int, v T) {
+	_ = s.data[i] // Panic before copying for an index out of range.
+	s.own(0)
+	s.data[i] = v
+}
+
+// Append adds the values to the end of the slice, copying the elements
+// first when the backing array is shared.
+func (s *COWSlice[T]) Append(values ...T) {
+	s.own(len(values))
+	s.data = append(s.data, values...)
+}
+
+// Slice returns a view of the elements in [i, j) that shares the backing
+// array with s until either of them writes.
+func (s *COWSlice[T]) Slice(i int, j int) *COWSlice[T] {
+	v := COWSlice[T]{
+		data:  s.data[i:j:len(s.data)],
+		store: s.store,
+	}
+	s.store.views++
+
+	return &v
+}
+
+// View returns a view of all the elements. It is Slice(0, s.Len()).
+func (s *COWSlice[T]) View() *COWSlice[T] {
+	return s.Slice(0, len(s.data))
+}
+
+// Shared reports whether the backing array is used by other views.
+func (s *COWSlice[T]) Shared() bool {
+	return s.store.views > 1
+}
+
+// Copies returns the number of copies made by s, the views taken from it
+// and the views taken from those.
+func (s *COWSlice[T]) Copies() int {
+	return *s.store.copies
+}
+
+// All returns an iterator over the index and value of each element.
+func (s *COWSlice[T]) All() iter.Seq2[int, T] {
+	return slices.All(s.data)
+}
+
+// Values returns a regular slice with a copy of the elements.
+func (s *COWSlice[T]) Values() []T {
+	return slices.Clone(s.data)
+}
+
+// String implements the fmt.Stringer interface.
+func (s *COWSlice[T]) String() string {
+	return fmt.Sprint(s.data)
+}
+
+// own makes sure s is the only user of its backing array before a write.
+// The copy has room for extra more elements.
+func (s *COWSlice[T]) own(extra int) {
+	if s.store.views == 1 {
+		return
+	}
+
+	data := make([]T, len(s.data), len(s.data)+extra)
+	copy(data, s.data)
+	s.data = data
+
+	s.store.views--
+	*s.store.copies++
+
+	s.store = &cowStore{
+		views:  1,
+		copies: s.store.copies,
+	}
+}
+
+// SlicesExample9 is a sample program to show how copy on write removes the
+// surprises of sharing a backing array shown in SlicesExample3.
+func SlicesExample9() {
+	fruits := NewCOWSlice("Apple", "Orange", "Banana", "Grape", "Plum")
+
+	// Take a view of indexes 2 and 3. No elements are copied.
+	view := fruits.Slice(2, 4)
+	fmt.Println(fruits, view, "Shared:", view.Shared(), "Copies:", fruits.Copies())
+
+	// Change the value of index 0 of the view. The view gets its own copy
+	// and the original slice doesn't see the change.
+	view.Set(0, "CHANGED")
+	fmt.Println(fruits, view, "Shared:", view.Shared(), "Copies:", fruits.Copies())
+
+	// Appending to the view doesn't overwrite "Plum" either. The view owns
+	// its elements now so no more copies are needed.
+	view.Append("Kiwi")
+	fmt.Println(fruits, view, "Copies:", fruits.Copies())
+
+	// The original slice is the only user of its backing array again, so
+	// writing to it doesn't copy.
+	fruits.Set(4, "Peach")
+	fmt.Println(fruits, view, "Copies:", fruits.Copies())
+}
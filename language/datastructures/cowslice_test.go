@@ -0,0 +1,176 @@
+package datastructures
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCOWSlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		run    func(s *COWSlice[int]) *COWSlice[int]
+		copies int
+		orig   []int
+		view   []int
+	}{
+		{
+			name: "write without views",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				s.Set(0, 10)
+				s.Append(6)
+				return s
+			},
+			orig: []int{10, 2, 3, 4, 5, 6},
+			view: []int{10, 2, 3, 4, 5, 6},
+		},
+		{
+			name: "read through a view",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				return s.Slice(1, 3)
+			},
+			orig: []int{1, 2, 3, 4, 5},
+			view: []int{2, 3},
+		},
+		{
+			name: "write through a view",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				v := s.Slice(1, 3)
+				v.Set(0, 20)
+				return v
+			},
+			copies: 1,
+			orig:   []int{1, 2, 3, 4, 5},
+			view:   []int{20, 3},
+		},
+		{
+			name: "write to the original",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				v := s.Slice(1, 3)
+				s.Set(1, 20)
+				return v
+			},
+			copies: 1,
+			orig:   []int{1, 20, 3, 4, 5},
+			view:   []int{2, 3},
+		},
+		{
+			name: "append through a view",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				v := s.Slice(1, 3)
+				v.Append(30)
+				return v
+			},
+			copies: 1,
+			orig:   []int{1, 2, 3, 4, 5},
+			view:   []int{2, 3, 30},
+		},
+		{
+			name: "writes after owning",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				v := s.Slice(0, 2)
+				v.Set(0, 10)
+				v.Set(1, 20)
+				v.Append(30, 40)
+				s.Set(4, 50)
+				return v
+			},
+			copies: 1,
+			orig:   []int{1, 2, 3, 4, 50},
+			view:   []int{10, 20, 30, 40},
+		},
+		{
+			name: "view of a view",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				v := s.View()
+				w := v.Slice(2, 5)
+				v.Set(0, 10)
+				w.Set(0, 30)
+				s.Set(1, 20)
+				return w
+			},
+			copies: 2,
+			orig:   []int{1, 20, 3, 4, 5},
+			view:   []int{30, 4, 5},
+		},
+		{
+			name: "views of the original",
+			run: func(s *COWSlice[int]) *COWSlice[int] {
+				a := s.Slice(0, 1)
+				b := s.Slice(1, 2)
+				a.Set(0, 10)
+				b.Set(0, 20)
+				return b
+			},
+			copies: 2,
+			orig:   []int{1, 2, 3, 4, 5},
+			view:   []int{20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCOWSlice(1, 2, 3, 4, 5)
+			v := tt.run(s)
+
+			if got := s.Copies(); got != tt.copies {
+				t.Errorf("Copies = %d, want %d", got, tt.copies)
+			}
+			if got := v.Copies(); got != tt.copies {
+				t.Errorf("Copies of the view = %d, want %d", got, tt.copies)
+			}
+			if got := s.Values(); !slices.Equal(got, tt.orig) {
+				t.Errorf("original = %v, want %v", got, tt.orig)
+			}
+			if got := v.Values(); !slices.Equal(got, tt.view) {
+				t.Errorf("view = %v, want %v", got, tt.view)
+			}
+		})
+	}
+}
+
+func TestCOWSliceShared(t *testing.T) {
+	s := NewCOWSlice("a", "b", "c")
+	if s.Shared() {
+		t.Fatal("a new slice is shared")
+	}
+
+	v := s.Slice(0, 2)
+	if !s.Shared() || !v.Shared() {
+		t.Fatal("a slice and its view are not shared")
+	}
+
+	v.Set(1, "x")
+	if s.Shared() || v.Shared() {
+		t.Error("a slice and its view are still shared after a write")
+	}
+
+	// The values passed in are copied too.
+	values := []string{"a", "b"}
+	c := NewCOWSlice(values...)
+	c.Set(0, "x")
+	if values[0] != "a" {
+		t.Error("Set changed the values passed to NewCOWSlice")
+	}
+
+	// Values returns a copy as well.
+	out := c.Values()
+	out[1] = "y"
+	if c.Get(1) != "b" {
+		t.Error("changing the result of Values changed the slice")
+	}
+}
+
+func TestCOWSliceSetOutOfRange(t *testing.T) {
+	s := NewCOWSlice(1, 2, 3)
+	v := s.Slice(0, 2)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Set out of range didn't panic")
+		}
+		if v.Copies() != 0 {
+			t.Errorf("Set out of range made %d copies", v.Copies())
+		}
+	}()
+	v.Set(2, 0)
+}
@@ -0,0 +1,154 @@
+package datastructures
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// SliceHeader is a snapshot of the three words of a slice value along with
+// the memory range it can reach through its capacity.
+//
+// A slice doesn't record where its backing array starts or ends, so the
+// only part of the array that can be observed from the slice is the window
+// [Data, Data+Cap*ElemSize). Elements before Data are out of its reach.
+type SliceHeader struct {
+	Data     uintptr // Address of the first element.
+	Len      int     // Number of elements accessible through the slice.
+	Cap      int     // Number of elements available in the backing array.
+	ElemSize uintptr // Size in bytes of each element.
+	End      uintptr // One past the last byte the slice can reach through its capacity.
+}
+
+// String implements the fmt.Stringer interface.
+func (h SliceHeader) String() string {
+	return fmt.Sprintf("Data[%#x] Length[%d] Capacity[%d] ElemSize[%d] Range[%#x - %#x)",
+		h.Data, h.Len, h.Cap, h.ElemSize, h.Data, h.End)
+}
+
+// lenEnd returns one past the last byte accessible through the length.
+func (h SliceHeader) lenEnd() uintptr {
+	return h.Data + uintptr(h.Len)*h.ElemSize
+}
+
+// Inspect exposes the slice header for any type of slice.
+func Inspect[T any](s []T) SliceHeader {
+	var zero T
+	size := unsafe.Sizeof(zero)
+	data := uintptr(unsafe.Pointer(unsafe.SliceData(s)))
+
+	return SliceHeader{
+		Data:     data,
+		Len:      len(s),
+		Cap:      cap(s),
+		ElemSize: size,
+		End:      data + uintptr(cap(s))*size,
+	}
+}
+
+// Aliasing describes how two slices relate to each other in memory.
+type Aliasing struct {
+	// SharesBacking is true when the capacity windows of both slices
+	// overlap. Two different arrays never occupy the same memory, so
+	// overlapping windows means a shared backing array. The opposite
+	// doesn't hold: slices of one array whose windows don't overlap, like
+	// a[:2:2] and a[4:], look the same as slices of different arrays and
+	// are reported as not sharing.
+	SharesBacking bool
+
+	// Offset is the index in a where b starts. It is negative when b
+	// starts before a. Only meaningful when SharesBacking is true.
+	Offset int
+
+	// Visible is the number of elements both slices can read and write
+	// through their lengths. A change to one of those is seen by the other.
+	Visible int
+
+	// Reachable is the number of elements both slices can reach through
+	// their capacities.
+	Reachable int
+
+	// AppendAClobbersB is true when appending to a without growing writes
+	// over elements b can see, as in SlicesExample3. AppendBClobbersA is
+	// the same in the other direction.
+	AppendAClobbersB bool
+	AppendBClobbersA bool
+}
+
+// String implements the fmt.Stringer interface.
+func (a Aliasing) String() string {
+	if !a.SharesBacking {
+		return "Independent backing arrays"
+	}
+	return fmt.Sprintf("Shared backing array: Offset[%d] Visible[%d] Reachable[%d] AppendAClobbersB[%t] AppendBClobbersA[%t]",
+		a.Offset, a.Visible, a.Reachable, a.AppendAClobbersB, a.AppendBClobbersA)
+}
+
+// Alias reports whether the capacity windows of the two slices overlap and
+// how, which is all the slices can tell about their backing arrays.
+func Alias[T any](a, b []T) Aliasing {
+	ha, hb := Inspect(a), Inspect(b)
+
+	if ha.ElemSize == 0 || ha.Cap == 0 || hb.Cap == 0 {
+		return Aliasing{}
+	}
+
+	if overlap(ha.Data, ha.End, hb.Data, hb.End) == 0 {
+		return Aliasing{}
+	}
+
+	size := ha.ElemSize
+
+	return Aliasing{
+		SharesBacking:    true,
+		Offset:           int(int64(hb.Data-ha.Data) / int64(size)),
+		Visible:          int(overlap(ha.Data, ha.lenEnd(), hb.Data, hb.lenEnd()) / size),
+		Reachable:        int(overlap(ha.Data, ha.End, hb.Data, hb.End) / size),
+		AppendAClobbersB: overlap(ha.lenEnd(), ha.End, hb.Data, hb.lenEnd()) > 0,
+		AppendBClobbersA: overlap(hb.lenEnd(), hb.End, ha.Data, ha.lenEnd()) > 0,
+	}
+}
+
+// overlap returns the number of bytes shared by the ranges [s1, e1) and [s2, e2).
+func overlap(s1, e1, s2, e2 uintptr) uintptr {
+	start := max(s1, s2)
+	end := min(e1, e2)
+	if end <= start {
+		return 0
+	}
+	return end - start
+}
+
+// SlicesAdvancedExample2 is a sample program to show how to check which slices
+// share a backing array instead of comparing printed addresses.
+func SlicesAdvancedExample2() {
+	// Create a slice with a length of 5 elements and a capacity of 8.
+	slice1 := make([]string, 5, 8)
+	slice1[0] = "Apple"
+	slice1[1] = "Orange"
+	slice1[2] = "Banana"
+	slice1[3] = "Grape"
+	slice1[4] = "Plum"
+
+	// Take a slice of slice1 with indexes 2 and 3.
+	slice2 := slice1[2:4]
+
+	fmt.Println("slice1:", Inspect(slice1))
+	fmt.Println("slice2:", Inspect(slice2))
+
+	// slice2 starts at index 2 of slice1 and both see 2 elements. Appending
+	// to slice2 overwrites "Plum" since the capacity is shared.
+	fmt.Println(Alias(slice1, slice2))
+
+	// Using the third index limits the capacity of the new slice so append
+	// has to allocate a new backing array.
+	takeOneCapOne := slice1[2:3:3]
+	fmt.Println(Alias(slice1, takeOneCapOne))
+
+	takeOneCapOne = append(takeOneCapOne, "Kiwi")
+	fmt.Println(Alias(slice1, takeOneCapOne))
+
+	// A copy never shares the backing array.
+	slice3 := make([]string, len(slice1))
+	copy(slice3, slice1)
+	fmt.Println(Alias(slice1, slice3))
+}
@@ -0,0 +1,138 @@
+package datastructures
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestInspect(t *testing.T) {
+	s := make([]int32, 3, 10)
+	h := Inspect(s[1:])
+
+	if h.Data != uintptr(unsafe.Pointer(&s[1])) {
+		t.Errorf("Data = %#x, want the address of s[1] %p", h.Data, &s[1])
+	}
+	if h.Len != 2 || h.Cap != 9 || h.ElemSize != 4 {
+		t.Errorf("Len, Cap, ElemSize = %d, %d, %d, want 2, 9, 4", h.Len, h.Cap, h.ElemSize)
+	}
+	if h.End-h.Data != 9*4 {
+		t.Errorf("range [%#x - %#x) doesn't cover the capacity", h.Data, h.End)
+	}
+
+	if h := Inspect[string](nil); h.Data != 0 || h.Len != 0 || h.Cap != 0 || h.End != 0 {
+		t.Errorf("nil slice = %v", h)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	fruits := make([]string, 5, 8)
+	copied := make([]string, len(fruits))
+	copy(copied, fruits)
+	grown := append(fruits[2:3:3], "Kiwi")
+
+	array := [6]int{}
+
+	tests := []struct {
+		name string
+		a, b []string
+		want Aliasing
+	}{
+		{
+			name: "same slice",
+			a:    fruits, b: fruits,
+			want: Aliasing{SharesBacking: true, Visible: 5, Reachable: 8},
+		},
+		{
+			name: "sub slice",
+			a:    fruits, b: fruits[2:4],
+			want: Aliasing{SharesBacking: true, Offset: 2, Visible: 2, Reachable: 6, AppendBClobbersA: true},
+		},
+		{
+			name: "sub slice first",
+			a:    fruits[2:4], b: fruits,
+			want: Aliasing{SharesBacking: true, Offset: -2, Visible: 2, Reachable: 6, AppendAClobbersB: true},
+		},
+		{
+			name: "short slice",
+			a:    fruits[:2], b: fruits[3:5],
+			want: Aliasing{SharesBacking: true, Offset: 3, Reachable: 5, AppendAClobbersB: true},
+		},
+		{
+			name: "full slice expression",
+			a:    fruits, b: fruits[2:3:3],
+			want: Aliasing{SharesBacking: true, Offset: 2, Visible: 1, Reachable: 1},
+		},
+		{
+			name: "past the length",
+			a:    fruits, b: fruits[5:7],
+			want: Aliasing{SharesBacking: true, Offset: 5, Reachable: 3, AppendAClobbersB: true},
+		},
+		{
+			name: "append after full slice expression",
+			a:    fruits, b: grown,
+		},
+		{
+			name: "copy",
+			a:    fruits, b: copied,
+		},
+		{
+			name: "capacity limited before the other",
+			a:    fruits[:2:2], b: fruits[2:4],
+		},
+		{
+			name: "windows apart in one array",
+			a:    fruits[:2:2], b: fruits[4:],
+		},
+		{
+			name: "nil",
+			a:    fruits, b: nil,
+		},
+		{
+			name: "empty",
+			a:    fruits[:0:0], b: fruits,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Alias(tt.a, tt.b); got != tt.want {
+				t.Errorf("Alias =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+
+	// Elements wider than a byte give the offsets in elements.
+	if got := Alias(array[:], array[4:]); got.Offset != 4 || got.Visible != 2 || got.Reachable != 2 {
+		t.Errorf("Alias of an int array = %v", got)
+	}
+
+	// Values of a zero size type take no memory, so there is nothing to
+	// share.
+	empty := make([]struct{}, 4)
+	if got := Alias(empty, empty); got.SharesBacking {
+		t.Errorf("Alias of zero size elements = %v", got)
+	}
+}
+
+// TestAliasClobber checks the clobber flags against what append really does.
+func TestAliasClobber(t *testing.T) {
+	fruits := []string{"Apple", "Orange", "Banana", "Grape", "Plum", "", "", ""}[:5]
+
+	b := fruits[2:4]
+	if !Alias(fruits, b).AppendBClobbersA {
+		t.Fatal("AppendBClobbersA is false")
+	}
+	_ = append(b, "Kiwi")
+	if fruits[4] != "Kiwi" {
+		t.Errorf("append to b didn't write over fruits[4] = %q", fruits[4])
+	}
+
+	c := fruits[2:4:4]
+	if Alias(fruits, c).AppendBClobbersA {
+		t.Fatal("AppendBClobbersA is true with the capacity limited")
+	}
+	_ = append(c, "Lime")
+	if fruits[4] != "Kiwi" {
+		t.Errorf("append to c wrote over fruits[4] = %q", fruits[4])
+	}
+}
@@ -0,0 +1,207 @@
+package datastructures
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Standing is the position of a player on a leaderboard.
+type Standing struct {
+	Name  string
+	Score int
+	Rank  int // Players with the same score share a rank, as in 1, 2, 2, 4.
+}
+
+// Leaderboard keeps player scores ranked from highest to lowest. It is safe
+// for concurrent use.
+//
+// Scores live in a map for lookups and the players are kept in a slice sorted
+// by rank. A change in score moves a single player to its new position with a
+// binary search and one copy of the players in between, so nothing is
+// re-sorted on a write and rank queries are a binary search.
+type Leaderboard struct {
+	mu     sync.RWMutex
+	scores map[string]int
+	ranked []mapPlayer
+}
+
+// NewLeaderboard constructs an empty Leaderboard.
+func NewLeaderboard() *Leaderboard {
+	lb := Leaderboard{
+		scores: make(map[string]int),
+	}
+	return &lb
+}
+
+// Increment adds delta to the player's score and returns the new score.
+// Players that are not on the board are added with a score of delta.
+func (lb *Leaderboard) Increment(name string, delta int) int {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	old, ok := lb.scores[name]
+	if !ok {
+		p := mapPlayer{name, delta}
+		i, _ := slices.BinarySearchFunc(lb.ranked, p, byRank)
+		lb.ranked = slices.Insert(lb.ranked, i, p)
+		lb.scores[name] = delta
+		return delta
+	}
+
+	p := mapPlayer{name, old + delta}
+	lb.scores[name] = p.score
+
+	// Shift the players between the old and new position by one and drop
+	// the player into the gap.
+	i, _ := slices.BinarySearchFunc(lb.ranked, mapPlayer{name, old}, byRank)
+	switch {
+	case delta > 0:
+		j, _ := slices.BinarySearchFunc(lb.ranked[:i], p, byRank)
+		copy(lb.ranked[j+1:i+1], lb.ranked[j:i])
+		lb.ranked[j] = p
+
+	case delta < 0:
+		k, _ := slices.BinarySearchFunc(lb.ranked[i+1:], p, byRank)
+		copy(lb.ranked[i:i+k], lb.ranked[i+1:i+1+k])
+		lb.ranked[i+k] = p
+	}
+
+	return p.score
+}
+
+// Score returns the player's score and whether the player is on the board.
+func (lb *Leaderboard) Score(name string) (int, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	score, ok := lb.scores[name]
+	return score, ok
+}
+
+// Rank returns the player's rank, starting at 1, and whether the player is
+// on the board.
+func (lb *Leaderboard) Rank(name string) (int, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	score, ok := lb.scores[name]
+	if !ok {
+		return 0, false
+	}
+	return lb.rank(score), true
+}
+
+// Len returns the number of players on the board.
+func (lb *Leaderboard) Len() int {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	return len(lb.ranked)
+}
+
+// TopN returns the standings of the first n players.
+func (lb *Leaderboard) TopN(n int) []Standing {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	return lb.standings(0, min(max(n, 0), len(lb.ranked)))
+}
+
+// Around returns the standings of the player along with up to n players
+// ranked above and n players ranked below. It returns nil when the player
+// is not on the board.
+func (lb *Leaderboard) Around(name string, n int) []Standing {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	score, ok := lb.scores[name]
+	if !ok {
+		return nil
+	}
+
+	n = max(n, 0)
+	i, _ := slices.BinarySearchFunc(lb.ranked, mapPlayer{name, score}, byRank)
+	return lb.standings(max(i-n, 0), min(i+n+1, len(lb.ranked)))
+}
+
+// String implements the fmt.Stringer interface.
+func (lb *Leaderboard) String() string {
+	var b strings.Builder
+	for _, s := range lb.TopN(lb.Len()) {
+		fmt.Fprintf(&b, "%3d. %-10s %d\n", s.Rank, s.Name, s.Score)
+	}
+	return b.String()
+}
+
+// standings converts the players in ranked[lo:hi] into standings. The
+// caller must hold the lock.
+func (lb *Leaderboard) standings(lo int, hi int) []Standing {
+	list := make([]Standing, 0, hi-lo)
+	for _, p := range lb.ranked[lo:hi] {
+		list = append(list, Standing{
+			Name:  p.name,
+			Score: p.score,
+			Rank:  lb.rank(p.score),
+		})
+	}
+	return list
+}
+
+// rank returns one plus the number of players with a higher score. The
+// caller must hold the lock.
+func (lb *Leaderboard) rank(score int) int {
+	i, _ := slices.BinarySearchFunc(lb.ranked, score, func(p mapPlayer, score int) int {
+		return cmp.Compare(score, p.score)
+	})
+	return i + 1
+}
+
+// byRank orders players by highest score first and then by name, so every
+// player has a single position in the ranked slice.
+func byRank(a mapPlayer, b mapPlayer) int {
+	if c := cmp.Compare(b.score, a.score); c != 0 {
+		return c
+	}
+	return strings.Compare(a.name, b.name)
+}
+
+// MapsExample9 is a sample program to show how to keep scores updated by many
+// goroutines without the copy, modify and store dance of MapsExample6.
+func MapsExample9() {
+	lb := NewLeaderboard()
+
+	// Every goroutine adds points for a single player.
+	points := map[string]int{
+		"anna":  42,
+		"jacob": 21,
+		"bill":  21,
+		"lisa":  63,
+		"tom":   7,
+	}
+
+	var wg sync.WaitGroup
+	for name, score := range points {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range score {
+				lb.Increment(name, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Print(lb)
+
+	// Jacob and Bill share the third place.
+	rank, _ := lb.Rank("jacob")
+	fmt.Println("Jacob's rank:", rank)
+
+	// Look at the players right around Bill.
+	for _, s := range lb.Around("bill", 1) {
+		fmt.Printf("%d %s %d\n", s.Rank, s.Name, s.Score)
+	}
+}
@@ -0,0 +1,156 @@
+package datastructures
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestLeaderboard(t *testing.T) {
+	lb := NewLeaderboard()
+	lb.Increment("bill", 10)
+	lb.Increment("jill", 30)
+	lb.Increment("ed", 20)
+	lb.Increment("ana", 20)
+	lb.Increment("bill", 25)
+	lb.Increment("jill", -15)
+
+	want := []Standing{
+		{"bill", 35, 1},
+		{"ana", 20, 2},
+		{"ed", 20, 2},
+		{"jill", 15, 4},
+	}
+	if got := lb.TopN(10); !slices.Equal(got, want) {
+		t.Errorf("TopN(10) = %v, want %v", got, want)
+	}
+	if got := lb.TopN(2); !slices.Equal(got, want[:2]) {
+		t.Errorf("TopN(2) = %v, want %v", got, want[:2])
+	}
+	if got := lb.TopN(-1); len(got) != 0 {
+		t.Errorf("TopN(-1) = %v, want none", got)
+	}
+
+	if got := lb.Around("ed", 1); !slices.Equal(got, want[1:4]) {
+		t.Errorf("Around(ed, 1) = %v, want %v", got, want[1:4])
+	}
+	if got := lb.Around("bill", 1); !slices.Equal(got, want[:2]) {
+		t.Errorf("Around(bill, 1) = %v, want %v", got, want[:2])
+	}
+	if got := lb.Around("nobody", 1); got != nil {
+		t.Errorf("Around(nobody, 1) = %v, want nil", got)
+	}
+
+	for _, s := range want {
+		if rank, ok := lb.Rank(s.Name); !ok || rank != s.Rank {
+			t.Errorf("Rank(%s) = %d, %v, want %d", s.Name, rank, ok, s.Rank)
+		}
+		if score, ok := lb.Score(s.Name); !ok || score != s.Score {
+			t.Errorf("Score(%s) = %d, %v, want %d", s.Name, score, ok, s.Score)
+		}
+	}
+	if _, ok := lb.Rank("nobody"); ok {
+		t.Error("Rank(nobody) found a player")
+	}
+	if lb.Len() != len(want) {
+		t.Errorf("Len = %d, want %d", lb.Len(), len(want))
+	}
+}
+
+// TestLeaderboardConcurrent increments scores from many goroutines while
+// others read the board. Run it with -race.
+func TestLeaderboardConcurrent(t *testing.T) {
+	const (
+		writers = 8
+		rounds  = 500
+		players = 20
+	)
+
+	lb := NewLeaderboard()
+
+	var wg sync.WaitGroup
+
+	// Every read must see a board that is ranked.
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range rounds {
+				top := lb.TopN(players)
+				if !ranked(top) {
+					t.Errorf("TopN is not ranked: %v", top)
+					return
+				}
+				if len(top) > 0 {
+					lb.Around(top[len(top)/2].Name, 2)
+					if rank, ok := lb.Rank(top[0].Name); ok && rank < 1 {
+						t.Errorf("Rank(%s) = %d", top[0].Name, rank)
+					}
+				}
+			}
+		}()
+	}
+
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range rounds {
+				name := fmt.Sprintf("player%02d", (w+r)%players)
+				delta := r%7 - 2
+				lb.Increment(name, delta)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// Work out the scores every player must end up with.
+	want := make(map[string]int)
+	for w := range writers {
+		for r := range rounds {
+			want[fmt.Sprintf("player%02d", (w+r)%players)] += r%7 - 2
+		}
+	}
+
+	top := lb.TopN(players + 1)
+	if len(top) != players {
+		t.Fatalf("%d players on the board, want %d", len(top), players)
+	}
+	if !ranked(top) {
+		t.Errorf("final board is not ranked: %v", top)
+	}
+	for _, s := range top {
+		if s.Score != want[s.Name] {
+			t.Errorf("%s has %d, want %d", s.Name, s.Score, want[s.Name])
+		}
+		if rank, _ := lb.Rank(s.Name); rank != s.Rank {
+			t.Errorf("Rank(%s) = %d, TopN says %d", s.Name, rank, s.Rank)
+		}
+	}
+}
+
+// ranked reports whether the standings are ordered by score, then name,
+// with ranks that count the players with a higher score.
+func ranked(list []Standing) bool {
+	for i, s := range list {
+		if i == 0 {
+			if s.Rank != 1 {
+				return false
+			}
+			continue
+		}
+
+		prev := list[i-1]
+		switch {
+		case s.Score > prev.Score:
+			return false
+		case s.Score == prev.Score && (s.Name < prev.Name || s.Rank != prev.Rank):
+			return false
+		case s.Score < prev.Score && s.Rank != i+1:
+			return false
+		}
+	}
+	return true
+}
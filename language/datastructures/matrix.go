@@ -0,0 +1,243 @@
+package datastructures
+
+import (
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"strings"
+)
+
+// Index identifies an element of a matrix.
+type Index struct {
+	Row int
+	Col int
+}
+
+// tileSize is the width and height of the tiles used by blocked traversal.
+// A 32x32 tile of 8 byte values is 8KB which fits in any L1 cache.
+const tileSize = 32
+
+// Matrix is a two dimensional matrix stored in a single slice in row major
+// order: the elements of row 0 come first, followed by the elements of row 1
+// and so on. Walking along a row reads contiguous memory while walking down a
+// column jumps a full row of elements at every step.
+type Matrix[T any] struct {
+	rows int
+	cols int
+	data []T
+}
+
+// NewMatrix constructs a matrix with the specified dimensions where every
+// element is set to its zero value.
+func NewMatrix[T any](rows int, cols int) *Matrix[T] {
+	if rows < 0 || cols < 0 {
+		panic(fmt.Sprintf("invalid matrix dimensions %dx%d", rows, cols))
+	}
+
+	m := Matrix[T]{
+		rows: rows,
+		cols: cols,
+		data: make([]T, rows*cols),
+	}
+	return &m
+}
+
+// Rows returns the number of rows.
+func (m *Matrix[T]) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns.
+func (m *Matrix[T]) Cols() int {
+	return m.cols
+}
+
+// At returns the element at the specified row and column.
+func (m *Matrix[T]) At(row int, col int) T {
+	return m.data[m.offset(row, col)]
+}
+
+// Set replaces the element at the specified row and column.
+func (m *Matrix[T]) Set(row int, col int, v T) {
+	m.data[m.offset(row, col)] = v
+}
+
+// Row returns the elements of a row. The slice shares the matrix's backing
+// array so changes made through it are seen by the matrix.
+func (m *Matrix[T]) Row(row int) []T {
+	if row < 0 || row >= m.rows {
+		panic(fmt.Sprintf("row %d out of range [0:%d]", row, m.rows))
+	}
+	start := row * m.cols
+	return m.data[start : start+m.cols : start+m.cols]
+}
+
+// RowMajor returns an iterator that walks the matrix one row at a time. It
+// visits the elements in the order they are stored in memory.
+func (m *Matrix[T]) RowMajor() iter.Seq2[Index, T] {
+	return func(yield func(Index, T) bool) {
+		for r := range m.rows {
+			row := m.data[r*m.cols : (r+1)*m.cols]
+			for c, v := range row {
+				if !yield(Index{r, c}, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ColMajor returns an iterator that walks the matrix one column at a time.
+// Every step moves a full row ahead in memory.
+func (m *Matrix[T]) ColMajor() iter.Seq2[Index, T] {
+	return func(yield func(Index, T) bool) {
+		for c := range m.cols {
+			for r := range m.rows {
+				if !yield(Index{r, c}, m.data[r*m.cols+c]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Tiles returns an iterator that walks the matrix one square tile at a time,
+// walking every tile in row major order. Column oriented work done tile by
+// tile keeps reusing the same few rows while they are still in the cache.
+func (m *Matrix[T]) Tiles(size int) iter.Seq2[Index, T] {
+	if size <= 0 {
+		size = tileSize
+	}
+
+	return func(yield func(Index, T) bool) {
+		for tr := 0; tr < m.rows; tr += size {
+			for tc := 0; tc < m.cols; tc += size {
+				for r := tr; r < min(tr+size, m.rows); r++ {
+					for c := tc; c < min(tc+size, m.cols); c++ {
+						if !yield(Index{r, c}, m.data[r*m.cols+c]) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// Transpose returns a new matrix with the rows and columns swapped. The copy
+// is done tile by tile so both the reads and the writes stay in the cache.
+func (m *Matrix[T]) Transpose() *Matrix[T] {
+	t := NewMatrix[T](m.cols, m.rows)
+	for idx, v := range m.Tiles(tileSize) {
+		t.data[idx.Col*t.cols+idx.Row] = v
+	}
+	return t
+}
+
+// transposeNaive returns a new matrix with the rows and columns swapped,
+// reading in row major order and writing in column major order.
+func (m *Matrix[T]) transposeNaive() *Matrix[T] {
+	t := NewMatrix[T](m.cols, m.rows)
+	for idx, v := range m.RowMajor() {
+		t.data[idx.Col*t.cols+idx.Row] = v
+	}
+	return t
+}
+
+// String implements the fmt.Stringer interface.
+func (m *Matrix[T]) String() string {
+	var b strings.Builder
+	for r := range m.rows {
+		fmt.Fprintln(&b, m.Row(r))
+	}
+	return b.String()
+}
+
+// offset converts a row and column into an index in data.
+func (m *Matrix[T]) offset(row int, col int) int {
+	if row < 0 || row >= m.rows || col < 0 || col >= m.cols {
+		panic(fmt.Sprintf("index [%d,%d] out of range [%dx%d]", row, col, m.rows, m.cols))
+	}
+	return row*m.cols + col
+}
+
+// =============================================================================
+
+// listNode is an element of a matrix stored as a linked list.
+type listNode[T any] struct {
+	value T
+	next  *listNode[T]
+}
+
+// listMatrix links the elements of a matrix in row major order. Every node is
+// a separate allocation, so walking the list follows pointers to wherever the
+// allocator happened to place each node.
+func listMatrix[T any](m *Matrix[T]) *listNode[T] {
+	nodes := make([]*listNode[T], len(m.data))
+
+	// Allocate the nodes in random order, like a program that built the list
+	// over time, so the next node is rarely the neighbor in memory.
+	for _, i := range rand.Perm(len(nodes)) {
+		nodes[i] = &listNode[T]{value: m.data[i]}
+	}
+	for i := range len(nodes) - 1 {
+		nodes[i].next = nodes[i+1]
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+// mapMatrix stores the elements of a matrix in a map keyed by their index.
+func mapMatrix[T any](m *Matrix[T]) map[Index]T {
+	elems := make(map[Index]T, len(m.data))
+	for idx, v := range m.RowMajor() {
+		elems[idx] = v
+	}
+	return elems
+}
+
+// ArraysExample7 is a sample program to show the order in which each traversal
+// visits the elements of a matrix and where those elements live in memory.
+// The benchmarks in matrix_test.go show how that order changes the cost of
+// reading the same data, with matrices that go from fitting in the L1 cache
+// to being larger than any last level cache.
+func ArraysExample7() {
+	m := NewMatrix[int](4, 6)
+	for idx := range m.RowMajor() {
+		m.Set(idx.Row, idx.Col, m.offset(idx.Row, idx.Col))
+	}
+	fmt.Print(m)
+
+	// Every value is the offset of the element in memory, so the values
+	// show how far each traversal jumps between steps.
+	walks := []struct {
+		name string
+		seq  iter.Seq2[Index, int]
+	}{
+		{"row major", m.RowMajor()},
+		{"col major", m.ColMajor()},
+		{"2x2 tiles", m.Tiles(2)},
+	}
+
+	for _, w := range walks {
+		fmt.Printf("%-10s", w.name)
+		for _, v := range w.seq {
+			fmt.Printf(" %2d", v)
+		}
+		fmt.Println()
+	}
+
+	// A linked list and a map hold the same values, but nothing about the
+	// order of the elements is left in where they are stored.
+	var list []int
+	for node := listMatrix(m); node != nil; node = node.next {
+		list = append(list, node.value)
+	}
+	fmt.Println("list:", list)
+	fmt.Println("map: ", len(mapMatrix(m)), "elements")
+
+	fmt.Print(m.Transpose())
+}
@@ -0,0 +1,310 @@
+package datastructures
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// countingMatrix returns a rows x cols matrix where every element holds its
+// offset in memory.
+func countingMatrix(rows int, cols int) *Matrix[int] {
+	m := NewMatrix[int](rows, cols)
+	for i := range m.data {
+		m.data[i] = i
+	}
+	return m
+}
+
+// matrixShapes are the dimensions the tests run against, including empty
+// matrices and sizes that don't divide into whole tiles.
+var matrixShapes = [][2]int{{0, 0}, {0, 3}, {3, 0}, {1, 1}, {1, 7}, {7, 1}, {5, 7}, {32, 32}, {33, 65}, {64, 31}}
+
+func TestMatrixTranspose(t *testing.T) {
+	for _, shape := range matrixShapes {
+		rows, cols := shape[0], shape[1]
+		m := countingMatrix(rows, cols)
+
+		for name, tr := range map[string]*Matrix[int]{"Transpose": m.Transpose(), "transposeNaive": m.transposeNaive()} {
+			if tr.Rows() != cols || tr.Cols() != rows {
+				t.Errorf("%s of %dx%d is %dx%d", name, rows, cols, tr.Rows(), tr.Cols())
+				continue
+			}
+			for r := range rows {
+				for c := range cols {
+					if tr.At(c, r) != m.At(r, c) {
+						t.Errorf("%s of %dx%d: [%d,%d] = %d, want %d", name, rows, cols, c, r, tr.At(c, r), m.At(r, c))
+					}
+				}
+			}
+		}
+
+		if back := m.Transpose().Transpose(); !slices.Equal(back.data, m.data) {
+			t.Errorf("transposing %dx%d twice changed it", rows, cols)
+		}
+	}
+}
+
+func TestMatrixTraversals(t *testing.T) {
+	for _, shape := range matrixShapes {
+		rows, cols := shape[0], shape[1]
+		m := countingMatrix(rows, cols)
+
+		// Row major visits the elements in the order they are stored.
+		var want []Index
+		for r := range rows {
+			for c := range cols {
+				want = append(want, Index{r, c})
+			}
+		}
+		checkWalk(t, fmt.Sprintf("RowMajor of %dx%d", rows, cols), m, m.RowMajor(), want)
+
+		want = want[:0]
+		for c := range cols {
+			for r := range rows {
+				want = append(want, Index{r, c})
+			}
+		}
+		checkWalk(t, fmt.Sprintf("ColMajor of %dx%d", rows, cols), m, m.ColMajor(), want)
+
+		// Tiles cover the edges with partial tiles and a size of zero or
+		// less means the default size.
+		for _, size := range []int{-1, 0, 1, 2, 3, tileSize, 100} {
+			tile := size
+			if tile <= 0 {
+				tile = tileSize
+			}
+
+			want = want[:0]
+			for tr := 0; tr < rows; tr += tile {
+				for tc := 0; tc < cols; tc += tile {
+					for r := tr; r < rows && r < tr+tile; r++ {
+						for c := tc; c < cols && c < tc+tile; c++ {
+							want = append(want, Index{r, c})
+						}
+					}
+				}
+			}
+			checkWalk(t, fmt.Sprintf("Tiles(%d) of %dx%d", size, rows, cols), m, m.Tiles(size), want)
+		}
+	}
+}
+
+// checkWalk checks the traversal visits exactly the indexes in want, in
+// order, with the values stored at them, and that it stops when asked to.
+func checkWalk(t *testing.T, name string, m *Matrix[int], seq iter.Seq2[Index, int], want []Index) {
+	t.Helper()
+
+	var got []Index
+	for idx, v := range seq {
+		if v != m.At(idx.Row, idx.Col) {
+			t.Errorf("%s: value at %v is %d, want %d", name, idx, v, m.At(idx.Row, idx.Col))
+			return
+		}
+		got = append(got, idx)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("%s visits %v, want %v", name, got, want)
+	}
+
+	var steps int
+	for range seq {
+		if steps++; steps == 3 {
+			break
+		}
+	}
+	if want := min(3, len(want)); steps != want {
+		t.Errorf("%s: stopping after 3 steps took %d", name, steps)
+	}
+}
+
+func TestMatrixRow(t *testing.T) {
+	m := countingMatrix(3, 4)
+
+	row := m.Row(1)
+	if !slices.Equal(row, []int{4, 5, 6, 7}) || cap(row) != 4 {
+		t.Fatalf("Row(1) = %v with cap %d", row, cap(row))
+	}
+
+	// The row shares the backing array but can't reach the next row.
+	row[0] = 40
+	if m.At(1, 0) != 40 {
+		t.Errorf("change through Row not seen, At(1, 0) = %d", m.At(1, 0))
+	}
+	_ = append(row, -1)
+	if m.At(2, 0) != 8 {
+		t.Errorf("append to Row(1) overwrote At(2, 0) = %d", m.At(2, 0))
+	}
+
+	m.Set(2, 3, 99)
+	if m.data[2*4+3] != 99 {
+		t.Errorf("Set(2, 3) wrote the wrong offset")
+	}
+
+	want := "[0 1 2 3]\n[40 5 6 7]\n[8 9 10 99]\n"
+	if m.String() != want {
+		t.Errorf("String = %q, want %q", m.String(), want)
+	}
+}
+
+func TestMatrixBounds(t *testing.T) {
+	m := countingMatrix(3, 4)
+
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"Row(-1)", func() { m.Row(-1) }},
+		{"Row(3)", func() { m.Row(3) }},
+		{"At(0, 4)", func() { m.At(0, 4) }}, // Within the data but past the end of the row.
+		{"At(-1, 0)", func() { m.At(-1, 0) }},
+		{"At(3, 0)", func() { m.At(3, 0) }},
+		{"At(1, -1)", func() { m.At(1, -1) }},
+		{"Set(3, 3)", func() { m.Set(3, 3, 0) }},
+		{"NewMatrix(-1, 2)", func() { NewMatrix[int](-1, 2) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s didn't panic", tt.name)
+				}
+			}()
+			tt.f()
+		})
+	}
+
+}
+
+func TestMatrixListAndMap(t *testing.T) {
+	m := countingMatrix(5, 7)
+
+	var list []int
+	for node := listMatrix(m); node != nil; node = node.next {
+		list = append(list, node.value)
+	}
+	if !slices.Equal(list, m.data) {
+		t.Errorf("list holds %v, want %v", list, m.data)
+	}
+
+	elems := mapMatrix(m)
+	for idx, v := range m.RowMajor() {
+		if elems[idx] != v {
+			t.Errorf("map[%v] = %d, want %d", idx, elems[idx], v)
+		}
+	}
+	if len(elems) != len(m.data) {
+		t.Errorf("map holds %d elements, want %d", len(elems), len(m.data))
+	}
+
+	if listMatrix(NewMatrix[int](0, 0)) != nil {
+		t.Error("list of an empty matrix isn't nil")
+	}
+}
+
+// =============================================================================
+
+// The benchmarks walk the same matrix in different orders and report the
+// time per element:
+//
+//	go test -run none -bench Matrix
+//
+// The matrices go from fitting in the L1 cache to being larger than any
+// last level cache. Row major order stays fast at every size while column
+// major order slows down once a column no longer fits in the cache.
+
+// matrixSizes are the widths of the square matrices to benchmark.
+var matrixSizes = []int{32, 128, 512, 1024, 2048, 4096}
+
+// matrixSink keeps the compiler from dropping the sums.
+var matrixSink int
+
+// benchMatrix runs a walk over every matrix size as a sub-benchmark. The
+// walk is prepared outside of the timed loop, and the name of every
+// sub-benchmark holds the size of the matrix in bytes.
+func benchMatrix(b *testing.B, maxSize int, prepare func(m *Matrix[int]) func() int) {
+	for _, size := range matrixSizes {
+		if size > maxSize {
+			break
+		}
+
+		m := countingMatrix(size, size)
+		n := size * size
+		walk := prepare(m)
+
+		b.Run(fmt.Sprintf("%dx%d/%dKB", size, size, n*strconv.IntSize/8/1024), func(b *testing.B) {
+			for range b.N {
+				matrixSink = walk()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(n), "ns/elem")
+		})
+	}
+}
+
+// sumWalk returns a walk that adds up the values of the sequence.
+func sumWalk(seq iter.Seq2[Index, int]) func() int {
+	return func() int {
+		var total int
+		for _, v := range seq {
+			total += v
+		}
+		return total
+	}
+}
+
+func BenchmarkMatrixRowMajor(b *testing.B) {
+	benchMatrix(b, 4096, func(m *Matrix[int]) func() int { return sumWalk(m.RowMajor()) })
+}
+
+func BenchmarkMatrixColMajor(b *testing.B) {
+	benchMatrix(b, 4096, func(m *Matrix[int]) func() int { return sumWalk(m.ColMajor()) })
+}
+
+func BenchmarkMatrixTiles(b *testing.B) {
+	benchMatrix(b, 4096, func(m *Matrix[int]) func() int { return sumWalk(m.Tiles(tileSize)) })
+}
+
+func BenchmarkMatrixTranspose(b *testing.B) {
+	benchMatrix(b, 4096, func(m *Matrix[int]) func() int {
+		return func() int { return m.Transpose().rows }
+	})
+}
+
+func BenchmarkMatrixTransposeNaive(b *testing.B) {
+	benchMatrix(b, 4096, func(m *Matrix[int]) func() int {
+		return func() int { return m.transposeNaive().rows }
+	})
+}
+
+// The list and map versions take too much memory for the larger sizes.
+
+func BenchmarkMatrixList(b *testing.B) {
+	benchMatrix(b, 1024, func(m *Matrix[int]) func() int {
+		head := listMatrix(m)
+		return func() int {
+			var total int
+			for node := head; node != nil; node = node.next {
+				total += node.value
+			}
+			return total
+		}
+	})
+}
+
+func BenchmarkMatrixMap(b *testing.B) {
+	benchMatrix(b, 1024, func(m *Matrix[int]) func() int {
+		elems := mapMatrix(m)
+		return func() int {
+			var total int
+			for r := range m.rows {
+				for c := range m.cols {
+					total += elems[Index{r, c}]
+				}
+			}
+			return total
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package datastructures
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// omEntry is a node in the list that remembers insertion order. A deleted
+// node keeps its next pointer so an iterator standing on it can move on.
+type omEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	prev    *omEntry[K, V]
+	next    *omEntry[K, V]
+	deleted bool
+}
+
+// OrderedMap is a map that iterates in the order keys were first inserted.
+// Lookups go through a regular map and the order is kept in a doubly linked
+// list, so Get, Set and Delete are all constant time. The zero value is
+// ready to use.
+type OrderedMap[K comparable, V any] struct {
+	entries map[K]*omEntry[K, V]
+	head    *omEntry[K, V]
+	tail    *omEntry[K, V]
+}
+
+// NewOrderedMap constructs an empty OrderedMap.
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{}
+}
+
+// Len returns the number of keys in the map.
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Get returns the value stored for the key and whether it is present.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.entries[key]; ok {
+		return e.value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Set stores the value for the key. Replacing the value of an existing key
+// keeps its original position.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if e, ok := m.entries[key]; ok {
+		e.value = value
+		return
+	}
+
+	if m.entries == nil {
+		m.entries = make(map[K]*omEntry[K, V])
+	}
+
+	e := omEntry[K, V]{
+		key:   key,
+		value: value,
+		prev:  m.tail,
+	}
+
+	if m.tail != nil {
+		m.tail.next = &e
+	} else {
+		m.head = &e
+	}
+	m.tail = &e
+	m.entries[key] = &e
+}
+
+// Delete removes the key from the map. It is safe to delete an absent key.
+func (m *OrderedMap[K, V]) Delete(key K) {
+	e, ok := m.entries[key]
+	if !ok {
+		return
+	}
+
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		m.head = e.next
+	}
+
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		m.tail = e.prev
+	}
+
+	e.deleted = true
+	delete(m.entries, key)
+}
+
+// All returns an iterator over the key/value pairs in insertion order.
+// Keys deleted during iteration are not produced once deleted. Keys added
+// during iteration may or may not be produced.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := m.head; e != nil; e = e.next {
+			if e.deleted {
+				continue
+			}
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the keys in insertion order.
+func (m *OrderedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values in insertion order.
+func (m *OrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface. The object members
+// are written in insertion order.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalPairs(m.All())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Keys are
+// inserted in the order they appear in the document.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	*m = OrderedMap[K, V]{}
+	return unmarshalPairs(data, m.Set)
+}
+
+// =============================================================================
+
+// marshalPairs writes the key/value pairs as a JSON object in the order
+// the iterator produces them.
+func marshalPairs[K comparable, V any](pairs iter.Seq2[K, V]) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	first := true
+	for k, v := range pairs {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		ks, err := encodeKey(k)
+		if err != nil {
+			return nil, err
+		}
+
+		kb, err := json.Marshal(ks)
+		if err != nil {
+			return nil, err
+		}
+
+		vb, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalPairs decodes a JSON object member by member and passes every
+// pair to set in document order.
+func unmarshalPairs[K comparable, V any](data []byte, set func(K, V)) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("expected JSON object, got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		var k K
+		if err := decodeKey(tok.(string), &k); err != nil {
+			return err
+		}
+
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+
+		set(k, v)
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+// encodeKey converts a map key into a JSON object member name following the
+// same rules as encoding/json: strings are used directly, even when they
+// implement encoding.TextMarshaler, other text marshalers are honored and
+// integers are formatted in base 10.
+func encodeKey(key any) (string, error) {
+	v := reflect.ValueOf(key)
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+
+	return "", fmt.Errorf("unsupported key type %T", key)
+}
+
+// decodeKey converts a JSON object member name back into a map key. As in
+// encodeKey, string keys are set directly.
+func decodeKey[K any](s string, key *K) error {
+	v := reflect.ValueOf(key).Elem()
+	if v.Kind() == reflect.String {
+		v.SetString(s)
+		return nil
+	}
+
+	if tu, ok := any(key).(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported key type %T", *key)
+}
@@ -0,0 +1,295 @@
+package datastructures
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// omOp is a change made to a map under test: a Set of the value, or a
+// Delete of the key when del is true.
+type omOp struct {
+	key   string
+	value int
+	del   bool
+}
+
+func TestOrderedMap(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []omOp
+		want []string
+	}{
+		{
+			name: "insertion order",
+			ops:  []omOp{{key: "c", value: 1}, {key: "a", value: 2}, {key: "b", value: 3}},
+			want: []string{"c=1", "a=2", "b=3"},
+		},
+		{
+			name: "replace keeps position",
+			ops:  []omOp{{key: "c", value: 1}, {key: "a", value: 2}, {key: "c", value: 9}},
+			want: []string{"c=9", "a=2"},
+		},
+		{
+			name: "delete head",
+			ops:  []omOp{{key: "a", value: 1}, {key: "b", value: 2}, {key: "c", value: 3}, {key: "a", del: true}},
+			want: []string{"b=2", "c=3"},
+		},
+		{
+			name: "delete middle",
+			ops:  []omOp{{key: "a", value: 1}, {key: "b", value: 2}, {key: "c", value: 3}, {key: "b", del: true}},
+			want: []string{"a=1", "c=3"},
+		},
+		{
+			name: "delete tail",
+			ops:  []omOp{{key: "a", value: 1}, {key: "b", value: 2}, {key: "c", value: 3}, {key: "c", del: true}},
+			want: []string{"a=1", "b=2"},
+		},
+		{
+			name: "delete absent",
+			ops:  []omOp{{key: "a", value: 1}, {key: "z", del: true}},
+			want: []string{"a=1"},
+		},
+		{
+			name: "delete all",
+			ops:  []omOp{{key: "a", value: 1}, {key: "b", value: 2}, {key: "b", del: true}, {key: "a", del: true}},
+		},
+		{
+			name: "insert after delete goes last",
+			ops:  []omOp{{key: "a", value: 1}, {key: "b", value: 2}, {key: "a", del: true}, {key: "a", value: 3}},
+			want: []string{"b=2", "a=3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m OrderedMap[string, int]
+			for _, op := range tt.ops {
+				if op.del {
+					m.Delete(op.key)
+					continue
+				}
+				m.Set(op.key, op.value)
+			}
+
+			var got []string
+			for k, v := range m.All() {
+				got = append(got, fmt.Sprintf("%s=%d", k, v))
+
+				if g, ok := m.Get(k); !ok || g != v {
+					t.Errorf("Get(%q) = %d, %v, want %d, true", k, g, ok, v)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("All = %v, want %v", got, tt.want)
+			}
+			if m.Len() != len(tt.want) {
+				t.Errorf("Len = %d, want %d", m.Len(), len(tt.want))
+			}
+
+			keys := slices.Collect(m.Keys())
+			values := slices.Collect(m.Values())
+			for i, pair := range tt.want {
+				if want := fmt.Sprintf("%s=%d", keys[i], values[i]); want != pair {
+					t.Errorf("Keys and Values give %s at %d, want %s", want, i, pair)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderedMapIteration(t *testing.T) {
+	m := NewOrderedMap[int, int]()
+	for i := range 6 {
+		m.Set(i, i*i)
+	}
+
+	// Deleting the current key must not stop the walk.
+	var seen []int
+	for k := range m.All() {
+		seen = append(seen, k)
+		if k%2 == 0 {
+			m.Delete(k)
+		}
+	}
+	if want := []int{0, 1, 2, 3, 4, 5}; !slices.Equal(seen, want) {
+		t.Errorf("walked %v while deleting, want %v", seen, want)
+	}
+	if got, want := slices.Collect(m.Keys()), []int{1, 3, 5}; !slices.Equal(got, want) {
+		t.Errorf("Keys = %v after deleting, want %v", got, want)
+	}
+
+	// Keys deleted ahead of the walk must not be produced.
+	seen = seen[:0]
+	for k := range m.All() {
+		seen = append(seen, k)
+		if k == 1 {
+			m.Delete(3)
+		}
+	}
+	if want := []int{1, 5}; !slices.Equal(seen, want) {
+		t.Errorf("walked %v while deleting ahead, want %v", seen, want)
+	}
+
+	// Breaking out of the loops must stop the iterators.
+	for range m.All() {
+		break
+	}
+	for range m.Keys() {
+		break
+	}
+	for range m.Values() {
+		break
+	}
+
+	if _, ok := m.Get(2); ok {
+		t.Error("Get found a deleted key")
+	}
+}
+
+// omName is a string key that is also marshaled as text. The encoding/json
+// documentation says keys of any string type are used directly, so the
+// text methods must be ignored.
+type omName string
+
+func (n omName) MarshalText() ([]byte, error) {
+	return []byte("text:" + n), nil
+}
+
+func (n *omName) UnmarshalText(text []byte) error {
+	*n = omName("text:" + string(text))
+	return nil
+}
+
+// omKey is a key that is marshaled as text.
+type omKey struct {
+	x int
+	y int
+}
+
+func (k omKey) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "%d,%d", k.x, k.y), nil
+}
+
+func (k *omKey) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &k.x, &k.y)
+	return err
+}
+
+func TestOrderedMapJSON(t *testing.T) {
+	t.Run("strings", func(t *testing.T) {
+		m := NewOrderedMap[string, []int]()
+		m.Set("zulu", []int{1})
+		m.Set("alpha", nil)
+		m.Set("mike", []int{2, 3})
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"zulu":[1],"alpha":null,"mike":[2,3]}`; string(data) != want {
+			t.Fatalf("Marshal = %s, want %s", data, want)
+		}
+
+		// Unmarshaling replaces what the map held.
+		got := NewOrderedMap[string, []int]()
+		got.Set("old", nil)
+		if err := json.Unmarshal(data, got); err != nil {
+			t.Fatal(err)
+		}
+		if keys := slices.Collect(got.Keys()); !slices.Equal(keys, []string{"zulu", "alpha", "mike"}) {
+			t.Errorf("Unmarshal keys = %v", keys)
+		}
+	})
+
+	t.Run("integers", func(t *testing.T) {
+		m := NewOrderedMap[int8, string]()
+		m.Set(-5, "a")
+		m.Set(3, "b")
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"-5":"a","3":"b"}`; string(data) != want {
+			t.Fatalf("Marshal = %s, want %s", data, want)
+		}
+
+		if err := json.Unmarshal([]byte(`{"300":"c"}`), m); err == nil {
+			t.Error("Unmarshal took a key out of the range of int8")
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		m := NewOrderedMap[omKey, bool]()
+		m.Set(omKey{2, 1}, true)
+		m.Set(omKey{1, 2}, false)
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"2,1":true,"1,2":false}`; string(data) != want {
+			t.Fatalf("Marshal = %s, want %s", data, want)
+		}
+
+		var got OrderedMap[omKey, bool]
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if keys := slices.Collect(got.Keys()); !slices.Equal(keys, []omKey{{2, 1}, {1, 2}}) {
+			t.Errorf("Unmarshal keys = %v", keys)
+		}
+	})
+
+	t.Run("string text", func(t *testing.T) {
+		m := NewOrderedMap[omName, int]()
+		m.Set("a", 1)
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"a":1}`; string(data) != want {
+			t.Fatalf("Marshal = %s, want %s", data, want)
+		}
+
+		var got OrderedMap[omName, int]
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if keys := slices.Collect(got.Keys()); !slices.Equal(keys, []omName{"a"}) {
+			t.Errorf("Unmarshal keys = %q", keys)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var m OrderedMap[string, int]
+		for _, data := range []string{`[1]`, `{"a":"x"}`, `{"a":1`} {
+			if err := json.Unmarshal([]byte(data), &m); err == nil {
+				t.Errorf("Unmarshal(%s) succeeded", data)
+			}
+		}
+
+		if _, err := json.Marshal(NewOrderedMap[float64, int]()); err != nil {
+			t.Errorf("Marshal of an empty map failed: %v", err)
+		}
+		floats := NewOrderedMap[float64, int]()
+		floats.Set(1.5, 1)
+		if _, err := json.Marshal(floats); err == nil {
+			t.Error("Marshal took a float key")
+		}
+	})
+
+	t.Run("null", func(t *testing.T) {
+		m := NewOrderedMap[string, int]()
+		m.Set("a", 1)
+		if err := json.Unmarshal([]byte(`null`), m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Len() != 0 {
+			t.Errorf("Len = %d after unmarshaling null, want 0", m.Len())
+		}
+	})
+}
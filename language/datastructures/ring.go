@@ -0,0 +1,269 @@
+package datastructures
+
+import (
+	"fmt"
+	"iter"
+	"sync/atomic"
+)
+
+// RingPolicy decides what Push does when a ring is full.
+type RingPolicy int
+
+// Set of policies for a full ring.
+const (
+	Overwrite RingPolicy = iota // Drop the oldest value to make room.
+	Reject                      // Refuse the new value.
+)
+
+// String implements the fmt.Stringer interface.
+func (p RingPolicy) String() string {
+	switch p {
+	case Overwrite:
+		return "overwrite"
+	case Reject:
+		return "reject"
+	}
+	return fmt.Sprintf("RingPolicy(%d)", int(p))
+}
+
+// RingArray is the set of array types a Ring can be built on. The length
+// of an array is part of its type, so the capacity of a Ring is fixed when
+// the type is written down. A type parameter can't stand for an array
+// length, so the lengths a Ring supports are listed here.
+type RingArray[T any] interface {
+	~[1]T | ~[2]T | ~[3]T | ~[4]T | ~[5]T | ~[6]T | ~[7]T | ~[8]T |
+		~[9]T | ~[10]T | ~[11]T | ~[12]T | ~[13]T | ~[14]T | ~[15]T | ~[16]T |
+		~[32]T | ~[64]T | ~[128]T | ~[256]T | ~[512]T | ~[1024]T | ~[2048]T | ~[4096]T
+}
+
+// Ring is a fixed capacity FIFO queue stored in an array of type A. The
+// values live inside the Ring itself in contiguous memory, so pushing and
+// popping never allocates and copying a Ring copies its values like any
+// other array. The zero value is an empty ring with the Overwrite policy.
+// A Ring is not safe for concurrent use.
+type Ring[T any, A RingArray[T]] struct {
+	buf    A
+	head   int // Index of the oldest value.
+	length int
+	policy RingPolicy
+}
+
+// NewRing constructs an empty Ring with the policy for when it is full.
+func NewRing[T any, A RingArray[T]](policy RingPolicy) *Ring[T, A] {
+	r := Ring[T, A]{
+		policy: policy,
+	}
+	return &r
+}
+
+// Len returns the number of values in the ring.
+func (r *Ring[T, A]) Len() int {
+	return r.length
+}
+
+// Cap returns the number of values the ring can hold.
+func (r *Ring[T, A]) Cap() int {
+	return len(r.buf)
+}
+
+// Push adds the value as the newest in the ring. It returns false when the
+// ring is full and the policy is Reject.
+func (r *Ring[T, A]) Push(v T) bool {
+	if r.length == len(r.buf) {
+		if r.policy == Reject {
+			return false
+		}
+
+		// Overwrite the oldest value and make the next one the oldest.
+		r.buf[r.head] = v
+		r.head = r.index(1)
+		return true
+	}
+
+	r.buf[r.index(r.length)] = v
+	r.length++
+	return true
+}
+
+// Pop removes and returns the oldest value. The boolean is false when the
+// ring is empty.
+func (r *Ring[T, A]) Pop() (T, bool) {
+	var zero T
+	if r.length == 0 {
+		return zero, false
+	}
+
+	// Clear the slot so the ring doesn't keep the value alive.
+	v := r.buf[r.head]
+	r.buf[r.head] = zero
+	r.head = r.index(1)
+	r.length--
+
+	return v, true
+}
+
+// PeekOldest returns the oldest value without removing it.
+func (r *Ring[T, A]) PeekOldest() (T, bool) {
+	if r.length == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.head], true
+}
+
+// PeekNewest returns the newest value without removing it.
+func (r *Ring[T, A]) PeekNewest() (T, bool) {
+	if r.length == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.index(r.length-1)], true
+}
+
+// All returns an iterator over the values from oldest to newest. The index
+// is the position from the oldest value.
+func (r *Ring[T, A]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := range r.length {
+			if !yield(i, r.buf[r.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the values from newest to oldest.
+func (r *Ring[T, A]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := r.length - 1; i >= 0; i-- {
+			if !yield(i, r.buf[r.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// index converts a position from the oldest value into an index in buf.
+func (r *Ring[T, A]) index(i int) int {
+	i += r.head
+	if i >= len(r.buf) {
+		i -= len(r.buf)
+	}
+	return i
+}
+
+// =============================================================================
+
+// cacheLinePad keeps the producer and consumer positions on different cache
+// lines so the two goroutines don't invalidate each other's cache.
+type cacheLinePad [64]byte
+
+// SPSCRing is a fixed capacity FIFO queue for exactly one goroutine pushing
+// and one goroutine popping at the same time. It doesn't use a lock: each
+// side owns one position and only reads the other side's position. The
+// capacity is rounded up to a power of two so positions can be masked into
+// an index. A full SPSCRing always rejects new values.
+type SPSCRing[T any] struct {
+	buf  []T
+	mask uint64
+	_    cacheLinePad
+	head atomic.Uint64 // Next position to pop, owned by the consumer.
+	_    cacheLinePad
+	tail atomic.Uint64 // Next position to push, owned by the producer.
+	_    cacheLinePad
+}
+
+// NewSPSCRing constructs a SPSCRing that holds at least capacity values.
+func NewSPSCRing[T any](capacity int) *SPSCRing[T] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("ring capacity must be positive, got %d", capacity))
+	}
+
+	size := 1
+	for size < capacity {
+		size <<= 1
+	}
+
+	r := SPSCRing[T]{
+		buf:  make([]T, size),
+		mask: uint64(size - 1),
+	}
+	return &r
+}
+
+// Cap returns the number of values the ring can hold.
+func (r *SPSCRing[T]) Cap() int {
+	return len(r.buf)
+}
+
+// Len returns the number of values in the ring. With both sides running
+// the answer may be out of date by the time it is used.
+func (r *SPSCRing[T]) Len() int {
+	head := r.head.Load()
+	return int(r.tail.Load() - head)
+}
+
+// Push adds the value as the newest in the ring. It returns false when the
+// ring is full. Only the producer goroutine may call Push.
+func (r *SPSCRing[T]) Push(v T) bool {
+	tail := r.tail.Load()
+	if tail-r.head.Load() == uint64(len(r.buf)) {
+		return false
+	}
+
+	// The value must be written before the new tail is published.
+	r.buf[tail&r.mask] = v
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// Pop removes and returns the oldest value. The boolean is false when the
+// ring is empty. Only the consumer goroutine may call Pop.
+func (r *SPSCRing[T]) Pop() (T, bool) {
+	var zero T
+
+	head := r.head.Load()
+	if head == r.tail.Load() {
+		return zero, false
+	}
+
+	// The slot must be read before the new head hands it back to the producer.
+	i := head & r.mask
+	v := r.buf[i]
+	r.buf[i] = zero
+	r.head.Store(head + 1)
+	return v, true
+}
+
+// =============================================================================
+
+// ArraysExample5 is a sample program to show how a fixed size array can be
+// used as a queue that keeps the most recent values.
+func ArraysExample5() {
+	// Keep the last 3 temperatures and drop the oldest one when full.
+	temps := NewRing[float64, [3]float64](Overwrite)
+	for _, t := range []float64{20.5, 21.0, 22.3, 23.1, 22.8} {
+		temps.Push(t)
+	}
+
+	for i, t := range temps.All() {
+		fmt.Println(i, t)
+	}
+
+	oldest, _ := temps.PeekOldest()
+	newest, _ := temps.PeekNewest()
+	fmt.Println("Oldest:", oldest, "Newest:", newest)
+
+	// A ring that rejects values when full.
+	jobs := NewRing[string, [2]string](Reject)
+	for _, job := range []string{"build", "test", "deploy"} {
+		if !jobs.Push(job) {
+			fmt.Println("Rejected:", job)
+		}
+	}
+
+	for jobs.Len() > 0 {
+		job, _ := jobs.Pop()
+		fmt.Println("Run:", job)
+	}
+}
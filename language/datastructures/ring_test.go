@@ -0,0 +1,297 @@
+package datastructures
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// ringValues returns the values of the ring from oldest to newest and from
+// newest to oldest.
+func ringValues[T any, A RingArray[T]](r *Ring[T, A]) ([]T, []T) {
+	var forward, backward []T
+	for _, v := range r.All() {
+		forward = append(forward, v)
+	}
+	for _, v := range r.Backward() {
+		backward = append(backward, v)
+	}
+	return forward, backward
+}
+
+func TestRingWrapAround(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RingPolicy
+		push   int
+		want   []int
+	}{
+		{"empty", Overwrite, 0, nil},
+		{"partial", Overwrite, 2, []int{0, 1}},
+		{"full", Overwrite, 4, []int{0, 1, 2, 3}},
+		{"overwrite once", Overwrite, 5, []int{1, 2, 3, 4}},
+		{"overwrite around", Overwrite, 11, []int{7, 8, 9, 10}},
+		{"reject", Reject, 11, []int{0, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRing[int, [4]int](tt.policy)
+			for i := range tt.push {
+				ok := r.Push(i)
+				if want := tt.policy == Overwrite || i < 4; ok != want {
+					t.Errorf("Push(%d) = %v, want %v", i, ok, want)
+				}
+			}
+
+			if r.Len() != len(tt.want) || r.Cap() != 4 {
+				t.Errorf("Len, Cap = %d, %d, want %d, 4", r.Len(), r.Cap(), len(tt.want))
+			}
+
+			forward, backward := ringValues(r)
+			if !slices.Equal(forward, tt.want) {
+				t.Errorf("All = %v, want %v", forward, tt.want)
+			}
+			slices.Reverse(backward)
+			if !slices.Equal(backward, tt.want) {
+				t.Errorf("Backward reversed = %v, want %v", backward, tt.want)
+			}
+
+			oldest, ok1 := r.PeekOldest()
+			newest, ok2 := r.PeekNewest()
+			if len(tt.want) == 0 {
+				if ok1 || ok2 {
+					t.Error("Peek found a value in an empty ring")
+				}
+				return
+			}
+			if oldest != tt.want[0] || newest != tt.want[len(tt.want)-1] || !ok1 || !ok2 {
+				t.Errorf("PeekOldest, PeekNewest = %d, %d", oldest, newest)
+			}
+		})
+	}
+}
+
+func TestRingPushPop(t *testing.T) {
+	r := NewRing[*int, [3]*int](Overwrite)
+
+	// Interleave pushes and pops so head runs around the array many
+	// times.
+	next, want := 0, 0
+	for round := range 50 {
+		for range round%3 + 1 {
+			v := next
+			r.Push(&v)
+			next++
+		}
+		for range round % 4 {
+			v, ok := r.Pop()
+			if !ok {
+				if r.Len() != 0 {
+					t.Fatalf("Pop failed with %d values", r.Len())
+				}
+				break
+			}
+
+			// Overwrite drops the oldest values the pops didn't get to.
+			want = max(want, next-3)
+			if *v != want {
+				t.Fatalf("round %d: Pop = %d, want %d", round, *v, want)
+			}
+			want++
+		}
+	}
+
+	// Popped slots are cleared so the ring doesn't keep values alive.
+	for r.Len() > 0 {
+		r.Pop()
+	}
+	for i, v := range r.buf {
+		if v != nil {
+			t.Errorf("slot %d still holds %d", i, *v)
+		}
+	}
+	if _, ok := r.Pop(); ok {
+		t.Error("Pop succeeded on an empty ring")
+	}
+}
+
+func TestRingValue(t *testing.T) {
+	// The zero value is ready to use and overwrites when full.
+	var r Ring[string, [2]string]
+	r.Push("a")
+
+	// The values are stored in the array inside the Ring, so a copy of
+	// the Ring is a copy of its values.
+	c := r
+	c.Push("b")
+	c.Push("c")
+
+	if got, _ := ringValues(&r); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("original = %v, want [a]", got)
+	}
+	if got, _ := ringValues(&c); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("copy = %v, want [b c]", got)
+	}
+	if c.Cap() != 2 {
+		t.Errorf("Cap = %d, want 2", c.Cap())
+	}
+}
+
+func TestRingIteratorStops(t *testing.T) {
+	r := NewRing[int, [4]int](Overwrite)
+	for i := range 6 {
+		r.Push(i)
+	}
+
+	var got []int
+	for i, v := range r.All() {
+		if i == 2 {
+			break
+		}
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{2, 3}) {
+		t.Errorf("All stopped at %v, want [2 3]", got)
+	}
+}
+
+func TestRingAllocs(t *testing.T) {
+	r := NewRing[int, [16]int](Overwrite)
+	s := NewSPSCRing[int](16)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Push(1)
+		r.Pop()
+		s.Push(1)
+		s.Pop()
+	})
+	if allocs != 0 {
+		t.Errorf("Push and Pop allocate %v times", allocs)
+	}
+}
+
+func TestSPSCRingCapacity(t *testing.T) {
+	for _, tt := range []struct{ capacity, want int }{{1, 1}, {3, 4}, {4, 4}, {5, 8}, {1000, 1024}} {
+		r := NewSPSCRing[int](tt.capacity)
+		if r.Cap() != tt.want {
+			t.Errorf("NewSPSCRing(%d).Cap() = %d, want %d", tt.capacity, r.Cap(), tt.want)
+		}
+
+		for i := range tt.want {
+			if !r.Push(i) {
+				t.Fatalf("Push %d of %d failed", i, tt.want)
+			}
+		}
+		if r.Push(-1) {
+			t.Errorf("Push to a full ring of %d succeeded", tt.want)
+		}
+		if v, ok := r.Pop(); !ok || v != 0 {
+			t.Errorf("Pop = %d, %v, want 0", v, ok)
+		}
+		if r.Len() != tt.want-1 {
+			t.Errorf("Len = %d, want %d", r.Len(), tt.want-1)
+		}
+	}
+}
+
+// TestSPSCRingConcurrent pushes from one goroutine and pops from another
+// and checks every value arrives once and in order. Run it with -race.
+func TestSPSCRingConcurrent(t *testing.T) {
+	const n = 100_000
+
+	r := NewSPSCRing[int](8)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range n {
+			for !r.Push(i) {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	for want := range n {
+		v, ok := r.Pop()
+		for !ok {
+			runtime.Gosched()
+			v, ok = r.Pop()
+		}
+		if v != want {
+			t.Fatalf("Pop = %d, want %d", v, want)
+		}
+	}
+	wg.Wait()
+
+	if r.Len() != 0 {
+		t.Errorf("Len = %d after popping everything", r.Len())
+	}
+}
+
+// =============================================================================
+
+// Once the ring is created pushing and popping values never allocates. The
+// benchmarks compare the ring and the SPSCRing with a buffered channel.
+
+func BenchmarkRing(b *testing.B) {
+	r := NewRing[int, [1024]int](Overwrite)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		r.Push(i)
+		if r.Len() == r.Cap() {
+			r.Pop()
+		}
+	}
+}
+
+func BenchmarkSPSCRing(b *testing.B) {
+	r := NewSPSCRing[int](1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range b.N {
+			for {
+				if _, ok := r.Pop(); ok {
+					break
+				}
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	for i := range b.N {
+		for !r.Push(i) {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+}
+
+func BenchmarkRingChannel(b *testing.B) {
+	ch := make(chan int, 1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range b.N {
+			<-ch
+		}
+	}()
+
+	for i := range b.N {
+		ch <- i
+	}
+	wg.Wait()
+}
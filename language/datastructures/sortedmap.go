@@ -0,0 +1,235 @@
+package datastructures
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// SortedMap is a map that iterates in ascending key order. Keys and values
+// are kept in two parallel slices sorted by key. Lookups are a binary search
+// and inserts and deletes shift the elements after the position, which on
+// contiguous memory is a fast copy. The zero value is ready to use.
+type SortedMap[K cmp.Ordered, V any] struct {
+	keys   []K
+	values []V
+
+	// readers counts the iterations reading the slices, so a change made
+	// during one works on a copy instead. version changes with every copy
+	// so iterations reading an older copy don't count themselves out.
+	readers int
+	version int
+}
+
+// NewSortedMap constructs an empty SortedMap.
+func NewSortedMap[K cmp.Ordered, V any]() *SortedMap[K, V] {
+	return &SortedMap[K, V]{}
+}
+
+// Len returns the number of keys in the map.
+func (m *SortedMap[K, V]) Len() int {
+	return len(m.keys)
+}
+
+// Get returns the value stored for the key and whether it is present.
+func (m *SortedMap[K, V]) Get(key K) (V, bool) {
+	if i, ok := slices.BinarySearch(m.keys, key); ok {
+		return m.values[i], true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Set stores the value for the key.
+func (m *SortedMap[K, V]) Set(key K, value V) {
+	m.unshare()
+
+	i, ok := slices.BinarySearch(m.keys, key)
+	if ok {
+		m.values[i] = value
+		return
+	}
+
+	m.keys = slices.Insert(m.keys, i, key)
+	m.values = slices.Insert(m.values, i, value)
+}
+
+// Delete removes the key from the map. It is safe to delete an absent key.
+func (m *SortedMap[K, V]) Delete(key K) {
+	i, ok := slices.BinarySearch(m.keys, key)
+	if !ok {
+		return
+	}
+
+	m.unshare()
+	m.keys = slices.Delete(m.keys, i, i+1)
+	m.values = slices.Delete(m.values, i, i+1)
+}
+
+// Min returns the smallest key and its value. The boolean is false when
+// the map is empty.
+func (m *SortedMap[K, V]) Min() (K, V, bool) {
+	if len(m.keys) == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return m.keys[0], m.values[0], true
+}
+
+// Max returns the largest key and its value. The boolean is false when
+// the map is empty.
+func (m *SortedMap[K, V]) Max() (K, V, bool) {
+	if len(m.keys) == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	last := len(m.keys) - 1
+	return m.keys[last], m.values[last], true
+}
+
+// All returns an iterator over the key/value pairs in ascending key order.
+func (m *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		keys, values, done := m.borrow()
+		defer done()
+
+		for i, k := range keys {
+			if !yield(k, values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the key/value pairs in descending
+// key order.
+func (m *SortedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		keys, values, done := m.borrow()
+		defer done()
+
+		for i := len(keys) - 1; i >= 0; i-- {
+			if !yield(keys[i], values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the pairs with keys in the half open
+// range [from, to), in ascending key order.
+func (m *SortedMap[K, V]) Range(from K, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		keys, values, done := m.borrow()
+		defer done()
+
+		lo, _ := slices.BinarySearch(keys, from)
+		hi, _ := slices.BinarySearch(keys, to)
+		for i := lo; i < hi; i++ {
+			if !yield(keys[i], values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the keys in ascending order.
+func (m *SortedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values in ascending key order.
+func (m *SortedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// borrow returns the slices for an iteration and a function to call when
+// the iteration finishes. While any iteration is reading the slices, the
+// next change works on a copy instead.
+func (m *SortedMap[K, V]) borrow() ([]K, []V, func()) {
+	m.readers++
+	version := m.version
+
+	done := func() {
+		// After a copy the iteration no longer reads the map's slices.
+		if m.version == version {
+			m.readers--
+		}
+	}
+	return m.keys, m.values, done
+}
+
+// unshare copies the slices when an iteration is still reading them.
+func (m *SortedMap[K, V]) unshare() {
+	if m.readers == 0 {
+		return
+	}
+	m.keys = slices.Clone(m.keys)
+	m.values = slices.Clone(m.values)
+	m.readers = 0
+	m.version++
+}
+
+// MarshalJSON implements the json.Marshaler interface. The object members
+// are written in ascending key order.
+func (m *SortedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalPairs(m.All())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *SortedMap[K, V]) UnmarshalJSON(data []byte) error {
+	*m = SortedMap[K, V]{}
+	return unmarshalPairs(data, m.Set)
+}
+
+// MapsExample8 is a sample program to show how to walk through a map by
+// insertion order and by key order without sorting the keys each time.
+func MapsExample8() {
+	// Declare an ordered map and add the users in the order they arrive.
+	arrivals := NewOrderedMap[string, mapUser]()
+	arrivals.Set("Roy", mapUser{"Rob", "Roy"})
+	arrivals.Set("Ford", mapUser{"Henry", "Ford"})
+	arrivals.Set("Mouse", mapUser{"Mickey", "Mouse"})
+	arrivals.Set("Jackson", mapUser{"Michael", "Jackson"})
+
+	// Iterating always follows insertion order.
+	for key, value := range arrivals.All() {
+		fmt.Println(key, value)
+	}
+
+	fmt.Println()
+
+	// Declare a sorted map and add the same users.
+	users := NewSortedMap[string, mapUser]()
+	for key, value := range arrivals.All() {
+		users.Set(key, value)
+	}
+
+	// Iterating always follows alphabetical key order.
+	for key, value := range users.All() {
+		fmt.Println(key, value)
+	}
+
+	fmt.Println()
+
+	// Walk just the keys from "G" up to but not including "N".
+	for key := range users.Range("G", "N") {
+		fmt.Println(key)
+	}
+}
@@ -0,0 +1,225 @@
+package datastructures
+
+import (
+	"encoding/json"
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// TestSortedMap applies random changes to a SortedMap and to a plain map
+// and checks that they always hold the same pairs, in key order.
+func TestSortedMap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	var m SortedMap[int, int]
+	want := make(map[int]int)
+
+	for i := range 2000 {
+		k := rng.Intn(100)
+		if rng.Intn(3) == 0 {
+			m.Delete(k)
+			delete(want, k)
+		} else {
+			m.Set(k, i)
+			want[k] = i
+		}
+
+		if m.Len() != len(want) {
+			t.Fatalf("step %d: Len = %d, want %d", i, m.Len(), len(want))
+		}
+		wv, present := want[k]
+		if v, ok := m.Get(k); ok != present || v != wv {
+			t.Fatalf("step %d: Get(%d) = %d, %v, want %d, %v", i, k, v, ok, wv, present)
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(want))
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, keys) {
+		t.Fatalf("Keys = %v, want %v", got, keys)
+	}
+	for k, v := range m.All() {
+		if want[k] != v {
+			t.Errorf("All gives %d for %d, want %d", v, k, want[k])
+		}
+	}
+
+	values := slices.Collect(m.Values())
+	for i, k := range keys {
+		if values[i] != want[k] {
+			t.Errorf("Values gives %d at %d, want %d", values[i], i, want[k])
+		}
+	}
+
+	var backward []int
+	for k := range m.Backward() {
+		backward = append(backward, k)
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(backward, keys) {
+		t.Errorf("Backward = %v reversed, want %v", backward, keys)
+	}
+
+	k, v, ok := m.Min()
+	if !ok || k != keys[0] || v != want[k] {
+		t.Errorf("Min = %d, %d, %v", k, v, ok)
+	}
+	k, v, ok = m.Max()
+	if !ok || k != keys[len(keys)-1] || v != want[k] {
+		t.Errorf("Max = %d, %d, %v", k, v, ok)
+	}
+}
+
+func TestSortedMapEmpty(t *testing.T) {
+	m := NewSortedMap[string, int]()
+	m.Delete("absent")
+
+	if _, ok := m.Get("absent"); ok {
+		t.Error("Get found a key in an empty map")
+	}
+	if _, _, ok := m.Min(); ok {
+		t.Error("Min found a key in an empty map")
+	}
+	if _, _, ok := m.Max(); ok {
+		t.Error("Max found a key in an empty map")
+	}
+	for k := range m.All() {
+		t.Errorf("All gives %q in an empty map", k)
+	}
+	for k := range m.Backward() {
+		t.Errorf("Backward gives %q in an empty map", k)
+	}
+}
+
+func TestSortedMapRange(t *testing.T) {
+	var m SortedMap[int, string]
+	for _, k := range []int{10, 20, 30, 40} {
+		m.Set(k, "")
+	}
+
+	tests := []struct {
+		from int
+		to   int
+		want []int
+	}{
+		{0, 100, []int{10, 20, 30, 40}},
+		{10, 40, []int{10, 20, 30}},
+		{15, 35, []int{20, 30}},
+		{20, 21, []int{20}},
+		{20, 20, nil},
+		{30, 10, nil},
+		{41, 50, nil},
+		{0, 10, nil},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for k := range m.Range(tt.from, tt.to) {
+			got = append(got, k)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Range(%d, %d) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSortedMapIteration(t *testing.T) {
+	var m SortedMap[int, int]
+	for i := range 5 {
+		m.Set(i, i)
+	}
+
+	// Changes made while iterating don't affect the walk.
+	var seen []int
+	for k := range m.All() {
+		seen = append(seen, k)
+		m.Delete(k)
+		m.Set(k+100, k)
+	}
+	if want := []int{0, 1, 2, 3, 4}; !slices.Equal(seen, want) {
+		t.Errorf("walked %v while changing the map, want %v", seen, want)
+	}
+	if got, want := slices.Collect(m.Keys()), []int{100, 101, 102, 103, 104}; !slices.Equal(got, want) {
+		t.Errorf("Keys = %v after the walk, want %v", got, want)
+	}
+
+	var values []int
+	for k, v := range m.Backward() {
+		values = append(values, v)
+		m.Set(k, -v)
+		m.Delete(k - 1)
+	}
+	if want := []int{4, 3, 2, 1, 0}; !slices.Equal(values, want) {
+		t.Errorf("walked back %v while changing the map, want %v", values, want)
+	}
+
+	// Once the walks are over, changes work on the map's own slices.
+	if m.readers != 0 {
+		t.Errorf("readers = %d after the walks, want 0", m.readers)
+	}
+	keys := m.keys
+	m.Set(keys[0], 7)
+	if &m.keys[0] != &keys[0] {
+		t.Error("Set copied the map after the walks finished")
+	}
+
+	// Nested walks keep reading their own slices after a change.
+	var outer, inner []int
+	for k := range m.Keys() {
+		outer = append(outer, k)
+		for k := range m.Keys() {
+			inner = append(inner, k)
+			m.Delete(k)
+		}
+	}
+	if want := []int{100, 101, 102, 103, 104}; !slices.Equal(outer, want) || !slices.Equal(inner, want) {
+		t.Errorf("nested walks saw %v and %v, want %v for both", outer, inner, want)
+	}
+	if m.Len() != 0 || m.readers != 0 {
+		t.Errorf("Len = %d, readers = %d after the nested walks, want 0 and 0", m.Len(), m.readers)
+	}
+
+	// Breaking out of the loops must stop the iterators.
+	for range m.All() {
+		break
+	}
+	for range m.Backward() {
+		break
+	}
+	for range m.Keys() {
+		break
+	}
+	for range m.Values() {
+		break
+	}
+}
+
+func TestSortedMapJSON(t *testing.T) {
+	m := NewSortedMap[string, int]()
+	m.Set("zulu", 1)
+	m.Set("alpha", 2)
+	m.Set("mike", 3)
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"alpha":2,"mike":3,"zulu":1}`; string(data) != want {
+		t.Fatalf("Marshal = %s, want %s", data, want)
+	}
+
+	got := NewSortedMap[string, int]()
+	got.Set("old", 0)
+	if err := json.Unmarshal([]byte(`{"zulu":1,"alpha":2,"mike":3}`), got); err != nil {
+		t.Fatal(err)
+	}
+	if keys := slices.Collect(got.Keys()); !slices.Equal(keys, []string{"alpha", "mike", "zulu"}) {
+		t.Errorf("Unmarshal keys = %v", keys)
+	}
+
+	var ints SortedMap[uint16, bool]
+	if err := json.Unmarshal([]byte(`{"70000":true}`), &ints); err == nil {
+		t.Error("Unmarshal took a key out of the range of uint16")
+	}
+}
@@ -1,7 +1,9 @@
 package decoupling
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // user defines a user in the program.
@@ -60,7 +62,8 @@ func MethodsExample1() {
 // *****************************************************************************
 
 // duration is a named type that represents a duration
-// of time in Nanosecond.
+// of time in Nanosecond. The methods it shares with time.Duration, like
+// Parse, String, Truncate and Round, are exported under the same names.
 type duration int64
 
 const (
@@ -72,16 +75,105 @@ const (
 	hour                 = 60 * minute
 )
 
+// fromDuration converts a time.Duration. Both count nanoseconds.
+func fromDuration(d time.Duration) duration {
+	return duration(d)
+}
+
 // setHours sets the specified number of hours.
 func (d *duration) setHours(h float64) {
-	*d = duration(h) * hour
+	*d = duration(h * float64(hour))
 }
 
 // hours returns the duration as a floating point number of hours.
 func (d duration) hours() float64 {
-	hour := d / hour
+	h := d / hour
 	nsec := d % hour
-	return float64(hour) + float64(nsec)*(1e-9/60/60)
+	return float64(h) + float64(nsec)/float64(hour)
+}
+
+// minutes returns the duration as a floating point number of minutes.
+func (d duration) minutes() float64 {
+	m := d / minute
+	nsec := d % minute
+	return float64(m) + float64(nsec)/float64(minute)
+}
+
+// seconds returns the duration as a floating point number of seconds.
+func (d duration) seconds() float64 {
+	sec := d / second
+	nsec := d % second
+	return float64(sec) + float64(nsec)/float64(second)
+}
+
+// Parse sets the duration from a string like "1h30m" or "1.5s" in the
+// format String writes. The duration is unchanged on error.
+func (d *duration) Parse(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// Truncate returns the duration rounded toward zero to a multiple of m.
+func (d duration) Truncate(m duration) duration {
+	return duration(time.Duration(d).Truncate(time.Duration(m)))
+}
+
+// Round returns the duration rounded to the nearest multiple of m, with
+// halfway values rounded away from zero.
+func (d duration) Round(m duration) duration {
+	return duration(time.Duration(d).Round(time.Duration(m)))
+}
+
+// toDuration converts the duration to a time.Duration.
+func (d duration) toDuration() time.Duration {
+	return time.Duration(d)
+}
+
+// String implements the fmt.Stringer interface. The format is the one
+// time.Duration uses, like 1h30m0s, which Parse reads back.
+func (d duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *duration) UnmarshalText(text []byte) error {
+	return d.Parse(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The duration is
+// written as a string so it reads the same as String.
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a
+// string like "1h30m" or a number of nanoseconds. Like the decoders of the
+// standard library, it leaves the duration unchanged for null.
+func (d *duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return d.UnmarshalText([]byte(s))
+	}
+
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("duration must be a string or a number of nanoseconds: %s", data)
+	}
+	*d = duration(n)
+	return nil
 }
 
 // MethodsExample2 is a sample program to show how to declare methods against
@@ -99,6 +191,60 @@ func MethodsExample2() {
 	fmt.Println("Hours:", dur.hours())
 }
 
+// MethodsExample5 is a sample program to show how methods give a named type
+// the behavior of time.Duration: parsing, formatting, rounding and
+// marshaling.
+func MethodsExample5() {
+	// Parse a duration and display it in every unit.
+	var dur duration
+	if err := dur.Parse("1h30m"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Duration:", dur)
+	fmt.Println("Hours:", dur.hours(), "Minutes:", dur.minutes(), "Seconds:", dur.seconds())
+
+	// A fraction of an hour is no longer lost.
+	dur.setHours(2.25)
+	fmt.Println("setHours(2.25):", dur, dur.hours())
+
+	// Truncate and round to a unit.
+	odd := 1*hour + 29*minute + 31*second + 500*millisecond
+	fmt.Println("Truncate:", odd.Truncate(minute), "Round:", odd.Round(minute))
+
+	// Convert to and from time.Duration to use it with the time package.
+	deadline := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC).Add(odd.toDuration())
+	fmt.Println("Deadline:", deadline.Format(time.Kitchen))
+	fmt.Println("From time.Duration:", fromDuration(90*time.Second))
+
+	// String writes what Parse reads, so the values round trip.
+	for _, d := range []duration{0, 1, -1500 * microsecond, 36*hour + 1, odd} {
+		var back duration
+		err := back.Parse(d.String())
+		fmt.Printf("%-18v round trips: %v\n", d, err == nil && back == d)
+	}
+
+	// JSON carries the duration as text and accepts nanoseconds as well.
+	type job struct {
+		Name    string   `json:"name"`
+		Timeout duration `json:"timeout"`
+	}
+
+	data, err := json.Marshal(job{"backup", 90 * minute})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(data))
+
+	var j job
+	if err := json.Unmarshal([]byte(`{"name":"sync","timeout":1500000000}`), &j); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%+v\n", j)
+}
+
 // *****************************************************************************
 
 // data is a struct to bind methods to.
@@ -0,0 +1,146 @@
+package decoupling
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	odd := 1*hour + 29*minute + 31*second + 500*millisecond
+
+	tests := []struct {
+		d       duration
+		str     string
+		hours   float64
+		minutes float64
+		seconds float64
+	}{
+		{0, "0s", 0, 0, 0},
+		{1, "1ns", 1 / float64(hour), 1 / float64(minute), 1e-9},
+		{-1500 * microsecond, "-1.5ms", -0.0015 / 3600, -0.0015 / 60, -0.0015},
+		{90 * minute, "1h30m0s", 1.5, 90, 5400},
+		{36*hour + 1, "36h0m0.000000001s", 36 + 1/float64(hour), 2160 + 1/float64(minute), 129600.000000001},
+		{odd, "1h29m31.5s", 5371.5 / 3600, 5371.5 / 60, 5371.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := tt.d.String(); got != tt.str {
+				t.Errorf("String = %q, want %q", got, tt.str)
+			}
+			if got := tt.d.hours(); !near(got, tt.hours) {
+				t.Errorf("hours = %v, want %v", got, tt.hours)
+			}
+			if got := tt.d.minutes(); !near(got, tt.minutes) {
+				t.Errorf("minutes = %v, want %v", got, tt.minutes)
+			}
+			if got := tt.d.seconds(); !near(got, tt.seconds) {
+				t.Errorf("seconds = %v, want %v", got, tt.seconds)
+			}
+			var got duration
+			if err := got.Parse(tt.str); err != nil || got != tt.d {
+				t.Errorf("Parse(%q) = %v, %v", tt.str, got, err)
+			}
+		})
+	}
+}
+
+func TestDurationRounding(t *testing.T) {
+	odd := 1*hour + 29*minute + 31*second + 500*millisecond
+
+	tests := []struct {
+		name string
+		got  duration
+		want duration
+	}{
+		{"Truncate hour", odd.Truncate(hour), hour},
+		{"Round hour", odd.Round(hour), 1 * hour},
+		{"Round minute", odd.Round(minute), 1*hour + 30*minute},
+		{"Round half second", odd.Round(second), 1*hour + 29*minute + 32*second},
+		{"Round negative half", (-odd).Round(second), -(1*hour + 29*minute + 32*second)},
+		{"Truncate negative", (-odd).Truncate(minute), -(1*hour + 29*minute)},
+		{"Truncate zero multiple", odd.Truncate(0), odd},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	var d duration
+	d.setHours(1.5)
+	if d != 90*minute {
+		t.Errorf("setHours(1.5) = %v, want 1h30m0s", d)
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	type record struct {
+		Timeout duration `json:"timeout"`
+	}
+
+	data, err := json.Marshal(record{90 * minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"timeout":"1h30m0s"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+
+	tests := []struct {
+		in   string
+		want duration
+		err  bool
+	}{
+		{in: `{"timeout":"1h30m0s"}`, want: 90 * minute},
+		{in: `{"timeout":"1.5s"}`, want: 1500 * millisecond},
+		{in: `{"timeout":1500}`, want: 1500},
+		{in: `{"timeout":"soon"}`, want: 5 * second, err: true},
+		{in: `{"timeout":true}`, want: 5 * second, err: true},
+		{in: `{"timeout":null}`, want: 5 * second},
+	}
+
+	for _, tt := range tests {
+		r := record{5 * second}
+		err := json.Unmarshal([]byte(tt.in), &r)
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("Unmarshal(%s) = %v, want an error", tt.in, r.Timeout)
+		case !tt.err && err != nil:
+			t.Errorf("Unmarshal(%s): %v", tt.in, err)
+		case r.Timeout != tt.want:
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.in, r.Timeout, tt.want)
+		}
+	}
+}
+
+// FuzzParse checks that every duration reads back from String.
+func FuzzParse(f *testing.F) {
+	for _, n := range []int64{0, 1, -1, 1500, int64(hour), int64(36*hour + 1), math.MaxInt64, math.MinInt64} {
+		f.Add(n)
+	}
+
+	f.Fuzz(func(t *testing.T, n int64) {
+		d := duration(n)
+
+		var got duration
+		if err := got.Parse(d.String()); err != nil {
+			t.Fatalf("Parse(%q): %v", d.String(), err)
+		}
+		if got != d {
+			t.Fatalf("Parse(%q) = %d, want %d", d.String(), got, n)
+		}
+		if d.toDuration() != time.Duration(n) || fromDuration(time.Duration(n)) != d {
+			t.Fatalf("conversion of %d to and from time.Duration changed it", n)
+		}
+	})
+}
+
+// near reports whether the floats are equal to within a relative error of
+// one in a billion.
+func near(a float64, b float64) bool {
+	return a == b || math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
@@ -0,0 +1,114 @@
+// Package analysis provides a small framework for writing static checks
+// against the course packages using only the standard library.
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// Severity describes how serious a reported problem is.
+type Severity int
+
+// Set of severities an analyzer can report with.
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+// String implements the fmt.Stringer interface.
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Diagnostic is a single problem reported by an analyzer.
+type Diagnostic struct {
+	Pos      token.Position
+	Severity Severity
+	Analyzer string
+	Message  string
+}
+
+// String implements the fmt.Stringer interface using the same
+// layout as the compiler and go vet.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.Pos, d.Severity, d.Message, d.Analyzer)
+}
+
+// Analyzer describes a check that runs against one package at a time.
+type Analyzer struct {
+	Name string
+	Doc  string
+	Run  func(*Pass) error
+}
+
+// Pass provides an analyzer with everything it needs to know about
+// the package being checked.
+type Pass struct {
+	Analyzer  *Analyzer
+	Fset      *token.FileSet
+	Files     []*ast.File
+	Pkg       *types.Package
+	TypesInfo *types.Info
+
+	diagnostics []Diagnostic
+}
+
+// Reportf records a diagnostic at the specified position.
+func (p *Pass) Reportf(pos token.Pos, sev Severity, format string, args ...any) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Pos:      p.Fset.Position(pos),
+		Severity: sev,
+		Analyzer: p.Analyzer.Name,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Run executes every analyzer against every package and returns the
+// diagnostics sorted by position.
+func Run(pkgs []*Package, analyzers ...*Analyzer) ([]Diagnostic, error) {
+	var diags []Diagnostic
+
+	for _, pkg := range pkgs {
+		for _, a := range analyzers {
+			pass := Pass{
+				Analyzer:  a,
+				Fset:      pkg.Fset,
+				Files:     pkg.Files,
+				Pkg:       pkg.Types,
+				TypesInfo: pkg.Info,
+			}
+
+			if err := a.Run(&pass); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", a.Name, pkg.Path, err)
+			}
+
+			diags = append(diags, pass.diagnostics...)
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		pi, pj := diags[i].Pos, diags[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+
+	return diags, nil
+}
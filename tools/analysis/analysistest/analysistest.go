@@ -0,0 +1,115 @@
+// Package analysistest runs an analyzer against a fixture package and checks
+// the diagnostics it reports against comments in the fixture.
+//
+// A comment of the form
+//
+//	// want "regexp"
+//
+// expects a diagnostic on its line whose severity and message, written as
+// "warning: message", match the regular expression. A comment can hold
+// several quoted expressions to expect several diagnostics on one line.
+// Every diagnostic must be expected and every expectation must be met, so
+// a fixture file without comments checks that nothing is reported.
+//
+// Fixtures live in testdata directories, which the go tool and lessonvet
+// both skip.
+package analysistest
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ultimate-go-programming/tools/analysis"
+)
+
+// expectation is a diagnostic a fixture expects on a line.
+type expectation struct {
+	pos token.Position
+	re  *regexp.Regexp
+}
+
+// Run loads the package in dir, runs the analyzer against it and reports
+// every mismatch between the diagnostics and the want comments as a test
+// error. It returns the diagnostics for further checks.
+func Run(t *testing.T, dir string, a *analysis.Analyzer) []analysis.Diagnostic {
+	t.Helper()
+
+	l, err := analysis.NewLoader(dir)
+	if err != nil {
+		t.Fatalf("NewLoader: %v", err)
+	}
+	pkg, err := l.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	diags, err := analysis.Run([]*analysis.Package{pkg}, a)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	wants, err := expectations(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range diags {
+		text := d.Severity.String() + ": " + d.Message
+
+		matched := false
+		for i, w := range wants {
+			if w.pos.Filename == d.Pos.Filename && w.pos.Line == d.Pos.Line && w.re.MatchString(text) {
+				wants = append(wants[:i], wants[i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			t.Errorf("%s: unexpected diagnostic: %s", d.Pos, text)
+		}
+	}
+
+	for _, w := range wants {
+		t.Errorf("%s: no diagnostic matching %q", w.pos, w.re)
+	}
+
+	return diags
+}
+
+// expectations collects the want comments of the package.
+func expectations(pkg *analysis.Package) ([]expectation, error) {
+	var wants []expectation
+
+	for _, file := range pkg.Files {
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				text, ok := strings.CutPrefix(c.Text, "// want ")
+				if !ok {
+					continue
+				}
+				pos := pkg.Fset.Position(c.Pos())
+
+				for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+					quoted, err := strconv.QuotedPrefix(text)
+					if err != nil {
+						return nil, fmt.Errorf("%s: want comment: %v", pos, err)
+					}
+					text = text[len(quoted):]
+
+					expr, _ := strconv.Unquote(quoted)
+					re, err := regexp.Compile(expr)
+					if err != nil {
+						return nil, fmt.Errorf("%s: want comment: %v", pos, err)
+					}
+					wants = append(wants, expectation{pos: pos, re: re})
+				}
+			}
+		}
+	}
+
+	return wants, nil
+}
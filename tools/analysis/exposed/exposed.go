@@ -0,0 +1,188 @@
+// Package exposed reports exported functions, methods and struct fields
+// whose types expose unexported named types to importers.
+//
+// ExportingExample3 shows counters.New returning the unexported type
+// alertCounter. It compiles, and importers can still hold the value, but
+// they can't name its type anywhere. That means no var declarations, no
+// function parameters or results, no struct fields, no conversions and no
+// type assertions. The type also disappears from the package documentation.
+package exposed
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"ultimate-go-programming/tools/analysis"
+)
+
+// Analyzer reports exported API that exposes unexported named types.
+var Analyzer = &analysis.Analyzer{
+	Name: "exposed",
+	Doc:  "report exported results and struct fields that expose unexported named types",
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) error {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				checkFunc(pass, decl)
+
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						checkStruct(pass, ts)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkFunc reports results of exported functions and of exported methods
+// declared on exported types.
+func checkFunc(pass *analysis.Pass, fn *ast.FuncDecl) {
+	if !fn.Name.IsExported() || fn.Type.Results == nil {
+		return
+	}
+
+	name := fn.Name.Name
+	if fn.Recv != nil {
+		recv := receiverName(fn.Recv)
+		if !ast.IsExported(recv) {
+			return
+		}
+		name = recv + "." + name
+	}
+	name = pass.Pkg.Name() + "." + name
+
+	for _, field := range fn.Type.Results.List {
+		for _, t := range unexported(pass.Pkg, pass.TypesInfo.TypeOf(field.Type)) {
+			pass.Reportf(field.Pos(), analysis.Warning,
+				"%s returns unexported type %s; %s",
+				name, t.Obj().Name(), explain(t))
+		}
+	}
+}
+
+// checkStruct reports exported fields of exported struct types.
+func checkStruct(pass *analysis.Pass, ts *ast.TypeSpec) {
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok || !ts.Name.IsExported() {
+		return
+	}
+
+	for _, field := range st.Fields.List {
+		for _, id := range field.Names {
+			if !id.IsExported() {
+				continue
+			}
+
+			for _, t := range unexported(pass.Pkg, pass.TypesInfo.TypeOf(field.Type)) {
+				pass.Reportf(id.Pos(), analysis.Warning,
+					"exported field %s.%s.%s has unexported type %s; %s",
+					pass.Pkg.Name(), ts.Name.Name, id.Name, t.Obj().Name(), explain(t))
+			}
+		}
+	}
+}
+
+// explain describes what importers can and can't do with values of the
+// unexported type.
+func explain(t *types.Named) string {
+	var methods []string
+	mset := types.NewMethodSet(types.NewPointer(t))
+	for i := range mset.Len() {
+		if m := mset.At(i).Obj(); m.Exported() {
+			methods = append(methods, m.Name())
+		}
+	}
+
+	possible := "importers can hold values with := and pass them to this package's own functions"
+	if b, ok := t.Underlying().(*types.Basic); ok {
+		possible += ", use the operators of " + b.Name()
+	}
+	if len(methods) > 0 {
+		possible += ", call " + strings.Join(methods, ", ")
+	}
+
+	return fmt.Sprintf("%s, but can't name the type in var declarations, parameters, results, struct fields, conversions or type assertions", possible)
+}
+
+// unexported returns every distinct unexported named type from the package
+// that appears in the type. Element, key and pointer types are followed so
+// *alertCounter and []alertCounter are found as well.
+func unexported(pkg *types.Package, t types.Type) []*types.Named {
+	var found []*types.Named
+	seen := make(map[types.Type]bool)
+
+	var walk func(t types.Type)
+	walk = func(t types.Type) {
+		if t == nil || seen[t] {
+			return
+		}
+		seen[t] = true
+
+		switch t := t.(type) {
+		case *types.Named:
+			if obj := t.Obj(); obj.Pkg() == pkg && !obj.Exported() {
+				found = append(found, t)
+			}
+			if args := t.TypeArgs(); args != nil {
+				for i := range args.Len() {
+					walk(args.At(i))
+				}
+			}
+
+		case *types.Pointer:
+			walk(t.Elem())
+		case *types.Slice:
+			walk(t.Elem())
+		case *types.Array:
+			walk(t.Elem())
+		case *types.Chan:
+			walk(t.Elem())
+		case *types.Map:
+			walk(t.Key())
+			walk(t.Elem())
+
+		case *types.Signature:
+			for i := range t.Params().Len() {
+				walk(t.Params().At(i).Type())
+			}
+			for i := range t.Results().Len() {
+				walk(t.Results().At(i).Type())
+			}
+		}
+	}
+	walk(t)
+
+	return found
+}
+
+// receiverName returns the base type name of a method receiver.
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+
+	expr := recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package exposed_test
+
+import (
+	"testing"
+
+	"ultimate-go-programming/tools/analysis/analysistest"
+	"ultimate-go-programming/tools/analysis/exposed"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, "testdata", exposed.Analyzer)
+}
@@ -0,0 +1,30 @@
+package counters
+
+// alertCounter is an unexported type.
+type alertCounter int
+
+func (a alertCounter) Add(n int) alertCounter {
+	return a + alertCounter(n)
+}
+
+// New returns the unexported type like the lesson does.
+func New(value int) alertCounter { // want `^warning: counters.New returns unexported type alertCounter; importers can hold values with := and pass them to this package's own functions, use the operators of int, call Add, but can't name the type`
+	return alertCounter(value)
+}
+
+type config struct{}
+
+// All reaches the unexported types through a slice, a pointer and a map.
+func All() ([]alertCounter, *config, map[string]config) { // want `returns unexported type alertCounter` `returns unexported type config; importers can hold values with := and pass them to this package's own functions, but` `returns unexported type config`
+	return nil, nil, nil
+}
+
+// Counter is exported, so its exported methods and fields are checked.
+type Counter struct {
+	Alerts alertCounter // want `^warning: exported field counters.Counter.Alerts has unexported type alertCounter`
+	Hook   func(config) // want `exported field counters.Counter.Hook has unexported type config`
+}
+
+func (c *Counter) Current() alertCounter { // want `^warning: counters.Counter.Current returns unexported type alertCounter`
+	return c.Alerts
+}
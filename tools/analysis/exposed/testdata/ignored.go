@@ -0,0 +1,38 @@
+package counters
+
+// AlertCounter is exported, so importers can name it.
+type AlertCounter int
+
+func NewAlert(value int) AlertCounter {
+	return AlertCounter(value)
+}
+
+// newCounter is unexported, so importers can't call it.
+func newCounter(value int) alertCounter {
+	return alertCounter(value)
+}
+
+// Unexported methods, methods of unexported types and unexported fields are
+// out of reach of importers.
+func (c *Counter) current() alertCounter {
+	return c.Alerts
+}
+
+type counter struct {
+	Value alertCounter
+}
+
+func (c counter) Get() alertCounter {
+	return c.Value
+}
+
+type Stats struct {
+	count alertCounter
+	Total int
+}
+
+// Parameters don't expose anything importers have to name.
+func Reset(c alertCounter) AlertCounter {
+	_ = newCounter(int(c))
+	return 0
+}
@@ -0,0 +1,364 @@
+package analysis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Package is a parsed and type-checked package from the module.
+type Package struct {
+	Dir   string
+	Path  string
+	Fset  *token.FileSet
+	Files []*ast.File
+	Types *types.Package
+	Info  *types.Info
+}
+
+// Loader parses and type-checks packages that belong to the module rooted
+// at the nearest go.mod. Without a go.mod the packages below the GOPATH src
+// directory that holds the code take the place of the module. Packages from
+// the module are loaded from source so type identity is shared between
+// everything the loader returns. Everything else is imported from compiler
+// export data.
+type Loader struct {
+	Fset *token.FileSet
+
+	root    string
+	module  string
+	std     types.Importer
+	pkgs    map[string]*Package
+	loading map[string]bool
+}
+
+// NewLoader constructs a loader for the module that contains dir.
+func NewLoader(dir string) (*Loader, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root, module, err := findModule(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	l := Loader{
+		Fset:    token.NewFileSet(),
+		root:    root,
+		module:  module,
+		std:     importer.Default(),
+		pkgs:    make(map[string]*Package),
+		loading: make(map[string]bool),
+	}
+
+	return &l, nil
+}
+
+// Load is a convenience function that constructs a loader for the current
+// directory and loads the specified patterns.
+func Load(patterns ...string) ([]*Package, error) {
+	l, err := NewLoader(".")
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Load(patterns...)
+}
+
+// Module returns the path of the module the loader is working with. It is
+// empty when the loader works with a GOPATH tree.
+func (l *Loader) Module() string {
+	return l.module
+}
+
+// Load loads every package matching the patterns. A pattern is a directory,
+// optionally followed by "/..." to include every directory below it.
+func (l *Loader) Load(patterns ...string) ([]*Package, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	var dirs []string
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "..."); ok {
+			found, err := packageDirs(filepath.Clean(dir))
+			if err != nil {
+				return nil, err
+			}
+			dirs = append(dirs, found...)
+			continue
+		}
+		dirs = append(dirs, pattern)
+	}
+
+	var pkgs []*Package
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		pkg, err := l.LoadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[pkg.Path] {
+			seen[pkg.Path] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].Path < pkgs[j].Path
+	})
+
+	return pkgs, nil
+}
+
+// LoadDir parses and type-checks the package in the specified directory.
+func (l *Loader) LoadDir(dir string) (*Package, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.load(l.importPath(abs), abs)
+}
+
+// Import implements the types.Importer interface.
+func (l *Loader) Import(path string) (*types.Package, error) {
+	var dir string
+	switch {
+	case l.module == "":
+		// In a GOPATH tree an import path is a directory below src, and
+		// the standard library is everything that isn't.
+		dir = filepath.Join(l.root, filepath.FromSlash(path))
+		if !hasGoFiles(dir) {
+			return l.std.Import(path)
+		}
+
+	case path == l.module || strings.HasPrefix(path, l.module+"/"):
+		dir = filepath.Join(l.root, filepath.FromSlash(strings.TrimPrefix(path, l.module)))
+
+	default:
+		return l.std.Import(path)
+	}
+
+	pkg, err := l.load(path, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg.Types, nil
+}
+
+// load returns the cached package for the import path or loads it.
+func (l *Loader) load(path string, dir string) (*Package, error) {
+	if pkg, ok := l.pkgs[path]; ok {
+		return pkg, nil
+	}
+
+	if l.loading[path] {
+		return nil, fmt.Errorf("import cycle through %s", path)
+	}
+	l.loading[path] = true
+	defer delete(l.loading, path)
+
+	files, err := l.parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info := types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+
+	conf := types.Config{
+		Importer: l,
+	}
+
+	tpkg, err := conf.Check(path, l.Fset, files, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := Package{
+		Dir:   dir,
+		Path:  path,
+		Fset:  l.Fset,
+		Files: files,
+		Types: tpkg,
+		Info:  &info,
+	}
+	l.pkgs[path] = &pkg
+
+	return &pkg, nil
+}
+
+// parseDir parses the non-test Go files inside the directory.
+func (l *Loader) parseDir(dir string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(l.Fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	return files, nil
+}
+
+// importPath calculates the import path for a directory inside the module.
+func (l *Loader) importPath(dir string) string {
+	rel, err := filepath.Rel(l.root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Base(dir)
+	}
+
+	if l.module == "" {
+		if rel == "." {
+			return filepath.Base(dir)
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	return path.Join(l.module, filepath.ToSlash(rel))
+}
+
+// findModule walks up from dir looking for a go.mod file and returns
+// the module root and module path. When there is no go.mod the GOPATH src
+// directory that holds dir is used as the root with an empty module path.
+// Outside of GOPATH the directory itself is used as the root and local
+// imports can't be resolved.
+func findModule(dir string) (string, string, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		f, err := os.Open(filepath.Join(d, "go.mod"))
+		if err == nil {
+			defer f.Close()
+
+			s := bufio.NewScanner(f)
+			for s.Scan() {
+				if module, ok := strings.CutPrefix(strings.TrimSpace(s.Text()), "module "); ok {
+					return d, strings.Trim(strings.TrimSpace(module), `"`), nil
+				}
+			}
+			if err := s.Err(); err != nil {
+				return "", "", err
+			}
+
+			return "", "", fmt.Errorf("%s: missing module directive", f.Name())
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+
+		if filepath.Dir(d) == d {
+			if src, ok := gopathSrc(dir); ok {
+				return src, "", nil
+			}
+			return dir, "", nil
+		}
+	}
+}
+
+// gopathSrc returns the src directory of the GOPATH entry that holds dir.
+func gopathSrc(dir string) (string, bool) {
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+
+	for _, p := range filepath.SplitList(gopath) {
+		src := filepath.Join(p, "src")
+		rel, err := filepath.Rel(src, dir)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return src, true
+		}
+	}
+
+	return "", false
+}
+
+// hasGoFiles reports whether the directory holds any non-test Go files.
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// packageDirs returns every directory below root that contains Go files,
+// skipping testdata and hidden directories.
+func packageDirs(root string) ([]string, error) {
+	var dirs []string
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasSuffix(p, ".go") && !strings.HasSuffix(p, "_test.go") {
+			dir := filepath.Dir(p)
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+
+		return nil
+	})
+
+	return dirs, err
+}
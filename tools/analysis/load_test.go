@@ -0,0 +1,120 @@
+package analysis_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/analysis/exposed"
+	"ultimate-go-programming/tools/analysis/prealloc"
+	"ultimate-go-programming/tools/analysis/shadow"
+)
+
+// TestLoadLesson runs the analyzers against a lesson package that imports
+// other packages of the course.
+func TestLoadLesson(t *testing.T) {
+	pkgs, err := analysis.Load("../../language/decoupling/...")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var lesson *analysis.Package
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.Path, "/language/decoupling") {
+			lesson = pkg
+		}
+	}
+	if lesson == nil {
+		t.Fatalf("language/decoupling not loaded, got %d packages", len(pkgs))
+	}
+
+	// The packages the lesson imports come from source, so they are the
+	// same packages the loader returned for their directories.
+	for _, imp := range lesson.Types.Imports() {
+		if !strings.Contains(imp.Path(), "/language/decoupling/packages/") {
+			continue
+		}
+		found := false
+		for _, pkg := range pkgs {
+			if pkg.Types == imp {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s was not loaded from source", imp.Path())
+		}
+	}
+
+	diags, err := analysis.Run(pkgs, exposed.Analyzer, prealloc.Analyzer, shadow.Analyzer)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// The lesson hides the builtin len on purpose in three functions.
+	var got []string
+	for _, d := range diags {
+		if filepath.Base(d.Pos.Filename) == "interfaces.go" {
+			got = append(got, fmt.Sprintf("%d %s %s", d.Pos.Line, d.Severity, d.Analyzer))
+		}
+	}
+	if want := "36 warning shadow|49 warning shadow|80 warning shadow"; strings.Join(got, "|") != want {
+		t.Errorf("diagnostics in interfaces.go = %q, want %q", strings.Join(got, "|"), want)
+	}
+}
+
+// TestLoadGOPATH loads a package from a GOPATH tree, where there is no
+// go.mod and import paths are directories below src.
+func TestLoadGOPATH(t *testing.T) {
+	gopath := t.TempDir()
+	write := func(name string, src string) {
+		path := filepath.Join(gopath, "src", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("course/counters/counters.go", "package counters\n\ntype AlertCounter int\n")
+	write("course/lesson/lesson.go", `package lesson
+
+import (
+	"fmt"
+
+	"course/counters"
+)
+
+func Print(c counters.AlertCounter) {
+	fmt.Println(c)
+}
+`)
+	t.Setenv("GOPATH", gopath)
+
+	l, err := analysis.NewLoader(filepath.Join(gopath, "src", "course", "lesson"))
+	if err != nil {
+		t.Fatalf("NewLoader: %v", err)
+	}
+	if l.Module() != "" {
+		t.Errorf("Module = %q, want empty", l.Module())
+	}
+
+	pkgs, err := l.Load(filepath.Join(gopath, "src", "course", "lesson"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Path != "course/lesson" {
+		t.Fatalf("loaded %v, want course/lesson", pkgs)
+	}
+
+	var paths []string
+	for _, imp := range pkgs[0].Types.Imports() {
+		paths = append(paths, imp.Path())
+	}
+	if got := strings.Join(paths, " "); got != "fmt course/counters" {
+		t.Errorf("imports = %q, want %q", got, "fmt course/counters")
+	}
+}
@@ -0,0 +1,474 @@
+// Package prealloc reports slices that are built by appending inside a loop
+// whose number of iterations is known before the loop starts.
+//
+// SlicesExample4 appends 1e5 records to a nil slice and MapsExample5 appends
+// every key of a map. In both cases the final length is known up front, so
+// the slice can be made with that capacity and append never has to grow
+// the backing array. When the number of iterations is a constant the report
+// also estimates how many allocations and bytes of copying are saved.
+package prealloc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"runtime"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/growth"
+)
+
+// Analyzer reports append loops that could use a preallocated slice.
+var Analyzer = &analysis.Analyzer{
+	Name: "prealloc",
+	Doc:  "suggest make with capacity for append loops with a known number of iterations",
+	Run:  run,
+}
+
+// sizes is used to calculate the size of slice elements.
+var sizes = types.SizesFor("gc", runtime.GOARCH)
+
+// bound describes how many times a loop runs.
+type bound struct {
+	expr  string // Source text for the capacity, like "len(users)".
+	count int64  // Number of iterations when known, otherwise -1.
+}
+
+func run(pass *analysis.Pass) error {
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if block, ok := n.(*ast.BlockStmt); ok {
+				checkBlock(pass, block.List)
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+// checkBlock looks for an empty slice declaration followed later in the same
+// block by a loop that appends to it once per iteration.
+func checkBlock(pass *analysis.Pass, stmts []ast.Stmt) {
+	for i, stmt := range stmts {
+		id, ok := emptySliceDecl(pass.TypesInfo, stmt)
+		if !ok {
+			continue
+		}
+		obj := pass.TypesInfo.Defs[id]
+
+		for _, next := range stmts[i+1:] {
+			if loop, b, ok := loopBound(pass, next); ok && appendsOnce(pass.TypesInfo, loop, obj) {
+				report(pass, id, obj, b)
+				break
+			}
+
+			if assigns(pass.TypesInfo, next, obj) {
+				break
+			}
+		}
+	}
+}
+
+// report formats the suggestion for the declaration.
+func report(pass *analysis.Pass, id *ast.Ident, obj types.Object, b bound) {
+	qual := func(p *types.Package) string {
+		if p == pass.Pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	typ := types.TypeString(obj.Type(), qual)
+	suggestion := fmt.Sprintf("%s := make(%s, 0, %s)", id.Name, typ, b.expr)
+
+	if b.count <= 0 {
+		pass.Reportf(id.Pos(), analysis.Info,
+			"%s is grown by append inside a loop with a known length; preallocate with %s",
+			id.Name, suggestion)
+		return
+	}
+
+	elem := obj.Type().Underlying().(*types.Slice).Elem()
+	cost := growth.Estimate(int(b.count), uintptr(sizes.Sizeof(elem)), hasPointers(elem))
+
+	pass.Reportf(id.Pos(), analysis.Info,
+		"%s is grown by append inside a loop of %d iterations; preallocate with %s to replace %d allocations and %d bytes of copying with a single allocation",
+		id.Name, b.count, suggestion, cost.Allocations, cost.Copied)
+}
+
+// emptySliceDecl reports whether the statement declares a single local slice
+// with no capacity, returning the declared identifier.
+func emptySliceDecl(info *types.Info, stmt ast.Stmt) (*ast.Ident, bool) {
+	var id *ast.Ident
+	var value ast.Expr
+
+	switch stmt := stmt.(type) {
+	case *ast.DeclStmt:
+		gen, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+			return nil, false
+		}
+		spec := gen.Specs[0].(*ast.ValueSpec)
+		if len(spec.Names) != 1 || len(spec.Values) > 1 {
+			return nil, false
+		}
+		id = spec.Names[0]
+		if len(spec.Values) == 1 {
+			value = spec.Values[0]
+		}
+
+	case *ast.AssignStmt:
+		if stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+			return nil, false
+		}
+		id, _ = stmt.Lhs[0].(*ast.Ident)
+		value = stmt.Rhs[0]
+
+	default:
+		return nil, false
+	}
+
+	if id == nil || info.Defs[id] == nil {
+		return nil, false
+	}
+
+	if _, ok := info.Defs[id].Type().Underlying().(*types.Slice); !ok {
+		return nil, false
+	}
+
+	return id, value == nil || isEmpty(info, value)
+}
+
+// isEmpty reports whether the expression produces a slice with no length
+// and no capacity: nil, an empty literal or make with a zero length.
+func isEmpty(info *types.Info, expr ast.Expr) bool {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.CompositeLit:
+		return len(expr.Elts) == 0
+
+	case *ast.CallExpr:
+		if tv, ok := info.Types[expr.Fun]; ok && tv.IsType() && len(expr.Args) == 1 {
+			return isEmpty(info, expr.Args[0])
+		}
+		if isBuiltin(info, expr.Fun, "make") && len(expr.Args) == 2 {
+			return isConst(info, expr.Args[1], 0)
+		}
+
+	case *ast.Ident:
+		return info.Uses[expr] == types.Universe.Lookup("nil")
+	}
+
+	return false
+}
+
+// loopBound reports whether the statement is a loop whose number of
+// iterations can be derived before it runs.
+func loopBound(pass *analysis.Pass, stmt ast.Stmt) (ast.Stmt, bound, bool) {
+	info := pass.TypesInfo
+
+	switch loop := stmt.(type) {
+	case *ast.RangeStmt:
+		tv, ok := info.Types[loop.X]
+		if !ok {
+			return nil, bound{}, false
+		}
+
+		switch t := tv.Type.Underlying().(type) {
+		case *types.Basic:
+			if t.Info()&types.IsInteger == 0 {
+				return nil, bound{}, false
+			}
+			if tv.Value != nil {
+				count, exact := constant.Int64Val(constant.ToInt(tv.Value))
+				if !exact || count <= 0 {
+					return nil, bound{}, false
+				}
+				return loop, bound{expr: fmt.Sprint(count), count: count}, true
+			}
+			if expr, ok := capacity(pass, loop.X); ok {
+				return loop, bound{expr: expr, count: -1}, true
+			}
+			return nil, bound{}, false
+
+		case *types.Array:
+			return loop, bound{expr: fmt.Sprint(t.Len()), count: t.Len()}, true
+
+		case *types.Pointer:
+			if a, ok := t.Elem().Underlying().(*types.Array); ok {
+				return loop, bound{expr: fmt.Sprint(a.Len()), count: a.Len()}, true
+			}
+
+		case *types.Slice, *types.Map:
+			if !isSimple(loop.X) {
+				return nil, bound{}, false
+			}
+			return loop, bound{expr: "len(" + render(pass.Fset, loop.X) + ")", count: -1}, true
+		}
+
+	case *ast.ForStmt:
+		if b, ok := forBound(pass, loop); ok {
+			return loop, b, true
+		}
+	}
+
+	return nil, bound{}, false
+}
+
+// forBound derives the number of iterations for a three clause loop that
+// counts up by one from its initial value.
+func forBound(pass *analysis.Pass, loop *ast.ForStmt) (bound, bool) {
+	info := pass.TypesInfo
+
+	init, ok := loop.Init.(*ast.AssignStmt)
+	if !ok || init.Tok != token.DEFINE || len(init.Lhs) != 1 || len(init.Rhs) != 1 {
+		return bound{}, false
+	}
+	counter, ok := init.Lhs[0].(*ast.Ident)
+	if !ok {
+		return bound{}, false
+	}
+	obj := info.Defs[counter]
+
+	post, ok := loop.Post.(*ast.IncDecStmt)
+	if !ok || post.Tok != token.INC || !isObj(info, post.X, obj) {
+		return bound{}, false
+	}
+
+	cond, ok := loop.Cond.(*ast.BinaryExpr)
+	if !ok || !isObj(info, cond.X, obj) || (cond.Op != token.LSS && cond.Op != token.LEQ) {
+		return bound{}, false
+	}
+
+	// The counter must only be changed by the post statement.
+	if assigns(info, loop.Body, obj) {
+		return bound{}, false
+	}
+
+	lo := info.Types[init.Rhs[0]].Value
+	hi := info.Types[cond.Y].Value
+	inclusive := cond.Op == token.LEQ
+
+	if lo != nil && hi != nil {
+		lo, hi = constant.ToInt(lo), constant.ToInt(hi)
+		n := constant.BinaryOp(hi, token.SUB, lo)
+		if inclusive {
+			n = constant.BinaryOp(n, token.ADD, constant.MakeInt64(1))
+		}
+
+		count, exact := constant.Int64Val(n)
+		if !exact || count <= 0 {
+			return bound{}, false
+		}
+		return bound{expr: fmt.Sprint(count), count: count}, true
+	}
+
+	// Without constants the capacity can still be written down when
+	// the loop counts from 0 up to n or from 1 through n.
+	if (isConst(info, init.Rhs[0], 0) && !inclusive) || (isConst(info, init.Rhs[0], 1) && inclusive) {
+		if expr, ok := capacity(pass, cond.Y); ok {
+			return bound{expr: expr, count: -1}, true
+		}
+	}
+
+	return bound{}, false
+}
+
+// capacity returns the source text for the capacity of a loop that runs n
+// times when n isn't a constant. The expression must be simple enough to
+// evaluate twice. A loop runs zero times when n is negative where make
+// would panic, so n is clamped at zero unless it is a length.
+func capacity(pass *analysis.Pass, n ast.Expr) (string, bool) {
+	if call, ok := ast.Unparen(n).(*ast.CallExpr); ok && isBuiltin(pass.TypesInfo, call.Fun, "len") && len(call.Args) == 1 {
+		if !isSimple(call.Args[0]) {
+			return "", false
+		}
+		return render(pass.Fset, n), true
+	}
+
+	if !isSimple(n) {
+		return "", false
+	}
+	return "max(" + render(pass.Fset, n) + ", 0)", true
+}
+
+// appendsOnce reports whether the loop body contains exactly one statement
+// of the form s = append(s, x), it is not nested inside another statement
+// and s is not assigned anywhere else in the body.
+func appendsOnce(info *types.Info, loop ast.Stmt, obj types.Object) bool {
+	var body *ast.BlockStmt
+	switch loop := loop.(type) {
+	case *ast.RangeStmt:
+		body = loop.Body
+	case *ast.ForStmt:
+		body = loop.Body
+	}
+
+	if skips(body) {
+		return false
+	}
+
+	var found int
+	for _, stmt := range body.List {
+		if isAppend(info, stmt, obj) {
+			found++
+			continue
+		}
+		if assigns(info, stmt, obj) {
+			return false
+		}
+	}
+
+	return found == 1
+}
+
+// skips reports whether the body contains a branch statement that can end
+// an iteration before the append runs or leave the loop early. Unlabeled
+// breaks inside nested loops, switches and selects belong to those
+// statements and are ignored.
+func skips(body *ast.BlockStmt) bool {
+	var found bool
+
+	var walk func(n ast.Node, nested bool)
+	walk = func(n ast.Node, nested bool) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if found {
+				return false
+			}
+
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				if !nested {
+					walk(n, true)
+					return false
+				}
+
+			case *ast.BranchStmt:
+				switch {
+				case n.Tok == token.GOTO, n.Label != nil:
+					found = true
+				case n.Tok == token.CONTINUE, n.Tok == token.BREAK:
+					found = !nested
+				}
+			}
+			return true
+		})
+	}
+	walk(body, false)
+
+	return found
+}
+
+// isAppend reports whether the statement appends a single element to the
+// slice and assigns the result back to it.
+func isAppend(info *types.Info, stmt ast.Stmt, obj types.Object) bool {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return false
+	}
+
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || !isBuiltin(info, call.Fun, "append") || call.Ellipsis.IsValid() || len(call.Args) != 2 {
+		return false
+	}
+
+	return isObj(info, assign.Lhs[0], obj) && isObj(info, call.Args[0], obj)
+}
+
+// assigns reports whether the node assigns to the object or takes its
+// address.
+func assigns(info *types.Info, node ast.Node, obj types.Object) bool {
+	var found bool
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if isObj(info, lhs, obj) {
+					found = true
+				}
+			}
+		case *ast.IncDecStmt:
+			if isObj(info, n.X, obj) {
+				found = true
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND && isObj(info, n.X, obj) {
+				found = true
+			}
+		}
+		return !found
+	})
+
+	return found
+}
+
+// isObj reports whether the expression is an identifier for the object.
+func isObj(info *types.Info, expr ast.Expr, obj types.Object) bool {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && obj != nil && info.ObjectOf(id) == obj
+}
+
+// isBuiltin reports whether the expression refers to the named builtin.
+func isBuiltin(info *types.Info, expr ast.Expr, name string) bool {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, ok = info.Uses[id].(*types.Builtin)
+	return ok && id.Name == name
+}
+
+// isConst reports whether the expression is the integer constant v.
+func isConst(info *types.Info, expr ast.Expr, v int64) bool {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil {
+		return false
+	}
+	return constant.Compare(constant.ToInt(tv.Value), token.EQL, constant.MakeInt64(v))
+}
+
+// hasPointers reports whether values of the type contain pointers the
+// garbage collector has to scan.
+func hasPointers(t types.Type) bool {
+	switch t := t.Underlying().(type) {
+	case *types.Basic:
+		return t.Kind() == types.String || t.Kind() == types.UnsafePointer
+	case *types.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case *types.Struct:
+		for i := range t.NumFields() {
+			if hasPointers(t.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// isSimple reports whether the expression can be evaluated a second time
+// for the capacity without repeating work or side effects.
+func isSimple(expr ast.Expr) bool {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isSimple(expr.X)
+	case *ast.StarExpr:
+		return isSimple(expr.X)
+	}
+	return false
+}
+
+// render returns the source text for an expression.
+func render(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
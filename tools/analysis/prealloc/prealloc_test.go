@@ -0,0 +1,12 @@
+package prealloc_test
+
+import (
+	"testing"
+
+	"ultimate-go-programming/tools/analysis/analysistest"
+	"ultimate-go-programming/tools/analysis/prealloc"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, "testdata", prealloc.Analyzer)
+}
@@ -0,0 +1,72 @@
+package prealloc
+
+type user struct {
+	name string
+	age  int
+}
+
+func names(users []user) []string {
+	var list []string // want `^info: list is grown by append inside a loop with a known length; preallocate with list := make\(\[\]string, 0, len\(users\)\)$`
+	for _, u := range users {
+		list = append(list, u.name)
+	}
+	return list
+}
+
+func keys(m map[string]int) []string {
+	keys := []string{} // want `preallocate with keys := make\(\[\]string, 0, len\(m\)\)$`
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func squares() []int {
+	var sq []int // want `^info: sq is grown by append inside a loop of 100 iterations; preallocate with sq := make\(\[\]int, 0, 100\) to replace \d+ allocations and \d+ bytes of copying with a single allocation$`
+	for i := 0; i < 100; i++ {
+		sq = append(sq, i*i)
+	}
+	return sq
+}
+
+func inclusive() []int {
+	s := make([]int, 0) // want `loop of 10 iterations; preallocate with s := make\(\[\]int, 0, 10\)`
+	for i := 1; i <= 10; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func array(a *[4]user) []int {
+	var ages []int // want `loop of 4 iterations; preallocate with ages := make\(\[\]int, 0, 4\)`
+	for _, u := range a {
+		ages = append(ages, u.age)
+	}
+	return ages
+}
+
+// A count that isn't constant can be negative, where the loop runs zero
+// times but make panics, so it is clamped.
+func upTo(n int) []int {
+	var s []int // want `with a known length; preallocate with s := make\(\[\]int, 0, max\(n, 0\)\)$`
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func rangeInt(c struct{ n int }) []int {
+	var s []int // want `preallocate with s := make\(\[\]int, 0, max\(c.n, 0\)\)$`
+	for i := range c.n {
+		s = append(s, i)
+	}
+	return s
+}
+
+func upToLen(users []user) []string {
+	var s []string // want `preallocate with s := make\(\[\]string, 0, len\(users\)\)$`
+	for i := 0; i < len(users); i++ {
+		s = append(s, users[i].name)
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+package prealloc
+
+func count() int {
+	return 3
+}
+
+// Bounds that aren't simple would be evaluated a second time by the
+// suggested make.
+func calls(ch chan int, users map[string][]user) []int {
+	var a []int
+	for i := 0; i < count(); i++ {
+		a = append(a, i)
+	}
+
+	var b []int
+	for i := 0; i < <-ch; i++ {
+		b = append(b, i)
+	}
+
+	var c []int
+	for i := range count() {
+		c = append(c, i)
+	}
+
+	var d []int
+	for i := 0; i < len(users["x"]); i++ {
+		d = append(d, i)
+	}
+
+	var e []user
+	for _, u := range users["x"] {
+		e = append(e, u)
+	}
+
+	return append(append(append(a, b...), c...), append(d, len(e))...)
+}
+
+// A constant loop that never runs has nothing to preallocate.
+func never() []int {
+	var s []int
+	for i := 0; i < -3; i++ {
+		s = append(s, i)
+	}
+	for range -3 {
+		s = append(s, 0)
+	}
+	return s
+}
+
+func conditional(users []user) []string {
+	var adults []string
+	for _, u := range users {
+		if u.age >= 18 {
+			adults = append(adults, u.name)
+		}
+	}
+
+	var named []string
+	for _, u := range users {
+		if u.name == "" {
+			continue
+		}
+		named = append(named, u.name)
+	}
+
+	return append(adults, named...)
+}
+
+func twice(users []user) []string {
+	var s []string
+	for _, u := range users {
+		s = append(s, u.name)
+		s = append(s, u.name)
+	}
+	return s
+}
+
+// The slice escapes through its address or is replaced inside the loop, so
+// its length isn't the number of iterations.
+func escapes(users []user, keep func(*[]string)) []string {
+	var s []string
+	for _, u := range users {
+		keep(&s)
+		s = append(s, u.name)
+	}
+
+	var t []string
+	p := &t
+	for _, u := range users {
+		t = append(t, u.name)
+	}
+	keep(p)
+
+	var r []string
+	for _, u := range users {
+		r = append(r, u.name)
+		if len(r) > 3 {
+			r = r[1:]
+		}
+	}
+
+	return append(append(s, t...), r...)
+}
+
+func counterChanged(n int) []int {
+	var s []int
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+		i++
+	}
+	return s
+}
+
+func alreadyMade(users []user) []string {
+	s := make([]string, 0, len(users))
+	for _, u := range users {
+		s = append(s, u.name)
+	}
+	return s
+}
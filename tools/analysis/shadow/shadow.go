@@ -0,0 +1,168 @@
+// Package shadow reports declarations that shadow predeclared identifiers
+// such as len or error, and local declarations that shadow package-level
+// constants.
+//
+// The lessons contain two flavors of this problem. In retrieveFile the
+// declaration `len, err := f.read(data)` hides the builtin len for the rest
+// of the function. A declaration like `hour := d / hour` in duration.hours
+// reads the package constant in its initializer and then every later use of
+// hour refers to the quotient instead. The second flavor is reported as an
+// error since the code almost never does what it reads like.
+package shadow
+
+import (
+	"go/ast"
+	"go/types"
+
+	"ultimate-go-programming/tools/analysis"
+)
+
+// Analyzer reports shadowed predeclared identifiers and package constants.
+var Analyzer = &analysis.Analyzer{
+	Name: "shadow",
+	Doc:  "report shadowing of predeclared identifiers and package-level constants",
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) error {
+	inits := initializers(pass.Files)
+
+	for id, obj := range pass.TypesInfo.Defs {
+		if obj == nil || id.Name == "_" || !declaresName(obj) {
+			continue
+		}
+
+		shadowed := lookupOuter(obj)
+		if shadowed == nil {
+			continue
+		}
+
+		var what string
+		switch {
+		case shadowed.Parent() == types.Universe:
+			what = "predeclared identifier"
+
+		case shadowed.Parent() == pass.Pkg.Scope():
+			if _, ok := shadowed.(*types.Const); !ok {
+				continue
+			}
+			what = "package constant"
+
+		default:
+			continue
+		}
+
+		if refersTo(pass.TypesInfo, inits[id], shadowed) {
+			pass.Reportf(id.Pos(), analysis.Error,
+				"declaration of %q shadows %s %q and refers to it in its own initializer; later uses of %q see the new %s",
+				id.Name, what, id.Name, id.Name, kind(obj))
+			continue
+		}
+
+		pass.Reportf(id.Pos(), analysis.Warning,
+			"declaration of %q shadows %s %q",
+			id.Name, what, id.Name)
+	}
+
+	return nil
+}
+
+// declaresName reports whether the object introduces a name into a lexical
+// scope. Fields and methods live in their own namespace and can't shadow.
+func declaresName(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.Var:
+		return !obj.IsField()
+	case *types.Func:
+		return obj.Type().(*types.Signature).Recv() == nil
+	case *types.Label:
+		return false
+	}
+	return obj.Parent() != nil
+}
+
+// lookupOuter finds the object the declaration hides, if any.
+func lookupOuter(obj types.Object) types.Object {
+	scope := obj.Parent()
+	if scope == nil || scope == types.Universe || scope.Parent() == nil {
+		return nil
+	}
+
+	_, outer := scope.Parent().LookupParent(obj.Name(), obj.Pos())
+	return outer
+}
+
+// kind returns a short description of the declared object.
+func kind(obj types.Object) string {
+	switch obj.(type) {
+	case *types.Const:
+		return "constant"
+	case *types.TypeName:
+		return "type"
+	case *types.Func:
+		return "function"
+	}
+	return "variable"
+}
+
+// refersTo reports whether any identifier inside the expressions resolves
+// to the specified object.
+func refersTo(info *types.Info, exprs []ast.Expr, obj types.Object) bool {
+	var found bool
+
+	for _, expr := range exprs {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && info.Uses[id] == obj {
+				found = true
+			}
+			return !found
+		})
+	}
+
+	return found
+}
+
+// initializers maps each identifier declared with an initial value to the
+// expressions that compute that value. When the number of names and values
+// differ, as with a multi-value function call, every name maps to the whole
+// right hand side.
+func initializers(files []*ast.File) map[*ast.Ident][]ast.Expr {
+	inits := make(map[*ast.Ident][]ast.Expr)
+
+	record := func(names []ast.Expr, values []ast.Expr) {
+		for i, name := range names {
+			id, ok := name.(*ast.Ident)
+			if !ok {
+				continue
+			}
+
+			if len(names) == len(values) {
+				inits[id] = values[i : i+1]
+				continue
+			}
+			inits[id] = values
+		}
+	}
+
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				record(n.Lhs, n.Rhs)
+
+			case *ast.ValueSpec:
+				names := make([]ast.Expr, len(n.Names))
+				for i, name := range n.Names {
+					names[i] = name
+				}
+				record(names, n.Values)
+
+			case *ast.RangeStmt:
+				record([]ast.Expr{n.Key, n.Value}, []ast.Expr{n.X})
+			}
+			return true
+		})
+	}
+
+	return inits
+}
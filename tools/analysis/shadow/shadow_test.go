@@ -0,0 +1,12 @@
+package shadow_test
+
+import (
+	"testing"
+
+	"ultimate-go-programming/tools/analysis/analysistest"
+	"ultimate-go-programming/tools/analysis/shadow"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, "testdata", shadow.Analyzer)
+}
@@ -0,0 +1,53 @@
+package shadow
+
+import "time"
+
+type file struct{}
+
+func (file) read(b []byte) (int, error) {
+	return copy(b, "data"), nil
+}
+
+// retrieveFile hides the builtin len like the lesson does.
+func retrieveFile(f file) error {
+	data := make([]byte, 100)
+
+	len, err := f.read(data) // want `^warning: declaration of "len" shadows predeclared identifier "len"$`
+	if err != nil {
+		return err
+	}
+
+	_ = data[:len]
+	return nil
+}
+
+func parse(error string) { // want `^warning: declaration of "error" shadows predeclared identifier "error"$`
+	_ = error
+}
+
+type duration int64
+
+const hour duration = 3600
+
+// hours reads the constant in the initializer of the variable that hides it.
+func (d duration) hours() float64 {
+	hour := d / hour // want `^error: declaration of "hour" shadows package constant "hour" and refers to it in its own initializer; later uses of "hour" see the new variable$`
+	return float64(hour)
+}
+
+const minute = time.Minute
+
+func wait() {
+	var minute = 5 // want `^warning: declaration of "minute" shadows package constant "minute"$`
+	_ = minute
+
+	for _, copy := range []int{1, 2, minute} { // want `^warning: declaration of "copy" shadows predeclared identifier "copy"$`
+		_ = copy
+	}
+}
+
+func new() {} // want `^warning: declaration of "new" shadows predeclared identifier "new"$`
+
+type (
+	string int // want `^warning: declaration of "string" shadows predeclared identifier "string"$`
+)
@@ -0,0 +1,33 @@
+package shadow
+
+// buffer has a field and a method with the names of builtins, which live
+// in their own namespace and hide nothing.
+type buffer struct {
+	len  int
+	data []byte
+}
+
+func (b buffer) cap() int {
+	return cap(b.data)
+}
+
+var total = 10
+
+// minutes uses names that don't hide anything, and hides a package
+// variable, which is allowed.
+func (d duration) minutes() float64 {
+	m := d / duration(minute)
+	total := int(m)
+	return float64(total) + float64(d%duration(minute))/float64(minute)
+}
+
+func count(items []int) int {
+	n := len(items)
+	for i := range n {
+		if i > 2 {
+			goto done
+		}
+	}
+done:
+	return n
+}
@@ -0,0 +1,387 @@
+// Package apicheck records the exported API of a set of packages and
+// compares it against a previously recorded snapshot.
+//
+// The API is flattened into features, one per line, in the spirit of the
+// files the Go project keeps under its api directory. Each feature has a
+// key, like "method (*Toy) UpdateOnHand", and a value, like its signature.
+// Comparing two snapshots then comes down to comparing keys and values.
+//
+//   - A feature that disappears breaks importers that use it.
+//   - A feature whose value changes breaks importers that use it.
+//   - A new feature is compatible, except for a new method on an existing
+//     interface, which breaks every type that implemented the interface.
+package apicheck
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+
+	"ultimate-go-programming/tools/analysis"
+	"ultimate-go-programming/tools/promotion"
+)
+
+// Feature is a single piece of exported API.
+type Feature struct {
+	Pkg   string
+	Key   string
+	Value string
+}
+
+// String implements the fmt.Stringer interface.
+func (f Feature) String() string {
+	if f.Value == "" {
+		return fmt.Sprintf("%s, %s", f.Pkg, f.Key)
+	}
+	return fmt.Sprintf("%s, %s %s", f.Pkg, f.Key, f.Value)
+}
+
+// id returns the identity used to match features between snapshots.
+func (f Feature) id() string {
+	return f.Pkg + "\t" + f.Key
+}
+
+// Snapshot returns the exported API of the packages in a stable order.
+func Snapshot(pkgs []*analysis.Package) ([]Feature, error) {
+	var features []Feature
+
+	for _, pkg := range pkgs {
+		f, err := packageFeatures(pkg.Types)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, f...)
+	}
+
+	sortFeatures(features)
+	return features, nil
+}
+
+// packageFeatures returns the exported API of a single package.
+func packageFeatures(pkg *types.Package) ([]Feature, error) {
+	var features []Feature
+	add := func(key string, value string) {
+		features = append(features, Feature{Pkg: pkg.Path(), Key: key, Value: value})
+	}
+
+	qual := types.RelativeTo(pkg)
+	scope := pkg.Scope()
+
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+
+		switch obj := obj.(type) {
+		case *types.Const:
+			add("const "+name, types.TypeString(obj.Type(), qual))
+
+		case *types.Var:
+			add("var "+name, types.TypeString(obj.Type(), qual))
+
+		case *types.Func:
+			add("func "+name, signature(obj, qual))
+
+		case *types.TypeName:
+			if obj.IsAlias() {
+				add("type "+name, "= "+types.TypeString(obj.Type(), qual))
+				continue
+			}
+
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+
+			f, err := typeFeatures(named, qual)
+			if err != nil {
+				return nil, err
+			}
+			for _, feature := range f {
+				add(feature[0], feature[1])
+			}
+		}
+	}
+
+	return features, nil
+}
+
+// typeFeatures returns the key and value pairs for a named type: its kind,
+// its exported fields including promoted ones, its interface methods and
+// its method sets.
+func typeFeatures(named *types.Named, qual types.Qualifier) ([][2]string, error) {
+	var features [][2]string
+	add := func(key string, value string) {
+		features = append(features, [2]string{key, value})
+	}
+
+	name := named.Obj().Name()
+
+	switch u := named.Underlying().(type) {
+	case *types.Struct:
+		add("type "+name, "struct")
+
+		r, err := promotion.Explore(named)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range r.Members {
+			if m.Kind != "field" || !token.IsExported(m.Name) {
+				continue
+			}
+			if m.Status == promotion.Declared || m.Status == promotion.Promoted {
+				add(fmt.Sprintf("type %s struct, %s", name, m.Name), fieldType(named, m, qual))
+			}
+		}
+
+	case *types.Interface:
+		add("type "+name, "interface")
+
+		var unexported bool
+		for i := range u.NumMethods() {
+			m := u.Method(i)
+			if !m.Exported() {
+				unexported = true
+				continue
+			}
+			add(fmt.Sprintf("type %s interface, %s", name, m.Name()), signature(m, qual))
+		}
+
+		if unexported {
+			add(fmt.Sprintf("type %s interface, unexported methods", name), "")
+		}
+
+		return features, nil
+
+	default:
+		add("type "+name, types.TypeString(u, qual))
+	}
+
+	// Methods in the value method set are available to both T and *T.
+	// Methods only in the pointer method set are recorded against *T.
+	value := types.NewMethodSet(named)
+	for i := range value.Len() {
+		if m := value.At(i).Obj().(*types.Func); m.Exported() {
+			add(fmt.Sprintf("method (%s) %s", name, m.Name()), signature(m, qual))
+		}
+	}
+
+	pointer := types.NewMethodSet(types.NewPointer(named))
+	for i := range pointer.Len() {
+		m := pointer.At(i).Obj().(*types.Func)
+		if !m.Exported() || value.Lookup(m.Pkg(), m.Name()) != nil {
+			continue
+		}
+		add(fmt.Sprintf("method (*%s) %s", name, m.Name()), signature(m, qual))
+	}
+
+	return features, nil
+}
+
+// fieldType looks up the type of a field reported by the promotion explorer.
+func fieldType(named *types.Named, m promotion.Member, qual types.Qualifier) string {
+	obj, _, _ := types.LookupFieldOrMethod(named, false, named.Obj().Pkg(), m.Name)
+	if obj == nil {
+		return m.Type
+	}
+	return types.TypeString(obj.Type(), qual)
+}
+
+// signature returns the signature of a function without parameter names,
+// so renaming a parameter is not reported as a change.
+func signature(fn *types.Func, qual types.Qualifier) string {
+	sig := fn.Type().(*types.Signature)
+
+	list := func(t *types.Tuple, variadic bool) []string {
+		s := make([]string, t.Len())
+		for i := range t.Len() {
+			typ := t.At(i).Type()
+			if variadic && i == t.Len()-1 {
+				s[i] = "..." + types.TypeString(typ.(*types.Slice).Elem(), qual)
+				continue
+			}
+			s[i] = types.TypeString(typ, qual)
+		}
+		return s
+	}
+
+	params := "(" + strings.Join(list(sig.Params(), sig.Variadic()), ", ") + ")"
+
+	results := list(sig.Results(), false)
+	switch len(results) {
+	case 0:
+		return params
+	case 1:
+		return params + " " + results[0]
+	}
+	return params + " (" + strings.Join(results, ", ") + ")"
+}
+
+// sortFeatures sorts features by package then key.
+func sortFeatures(features []Feature) {
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].id() < features[j].id()
+	})
+}
+
+// =============================================================================
+
+// snapshotHeader is written at the top of every snapshot file.
+const snapshotHeader = "# Exported API snapshot. Regenerate with: go run ./cmd/apicheck -update"
+
+// WriteSnapshot writes the features in the snapshot file format: one
+// feature per line with the package, key and value separated by tabs.
+func WriteSnapshot(w io.Writer, features []Feature) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, snapshotHeader)
+	for _, f := range features {
+		fmt.Fprintf(bw, "%s\t%s\t%s\n", f.Pkg, f.Key, f.Value)
+	}
+
+	return bw.Flush()
+}
+
+// ReadSnapshot reads features written by WriteSnapshot.
+func ReadSnapshot(r io.Reader) ([]Feature, error) {
+	var features []Feature
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		parts := strings.Split(text, "\t")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("snapshot line %d: expected 3 tab separated columns, got %d", line, len(parts))
+		}
+
+		features = append(features, Feature{Pkg: parts[0], Key: parts[1], Value: parts[2]})
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	sortFeatures(features)
+	return features, nil
+}
+
+// =============================================================================
+
+// Change describes a difference between the snapshot and the current API.
+type Change struct {
+	Old      *Feature
+	New      *Feature
+	Breaking bool
+	Reason   string
+}
+
+// String implements the fmt.Stringer interface.
+func (c Change) String() string {
+	class := "compatible"
+	if c.Breaking {
+		class = "BREAKING"
+	}
+
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("%s: added %s (%s)", class, c.New, c.Reason)
+	case c.New == nil:
+		return fmt.Sprintf("%s: removed %s (%s)", class, c.Old, c.Reason)
+	}
+	return fmt.Sprintf("%s: changed %s, %s: %s -> %s (%s)", class, c.Old.Pkg, c.Old.Key, c.Old.Value, c.New.Value, c.Reason)
+}
+
+// Compare classifies every difference between the recorded features and
+// the current ones.
+func Compare(recorded []Feature, current []Feature) []Change {
+	recordedByID := make(map[string]*Feature, len(recorded))
+	for i := range recorded {
+		recordedByID[recorded[i].id()] = &recorded[i]
+	}
+
+	currentByID := make(map[string]*Feature, len(current))
+	for i := range current {
+		currentByID[current[i].id()] = &current[i]
+	}
+
+	var changes []Change
+
+	for i := range recorded {
+		o := &recorded[i]
+		n, ok := currentByID[o.id()]
+
+		switch {
+		case !ok:
+			changes = append(changes, Change{
+				Old:      o,
+				Breaking: true,
+				Reason:   "importers using it no longer compile",
+			})
+
+		case n.Value != o.Value:
+			changes = append(changes, Change{
+				Old:      o,
+				New:      n,
+				Breaking: true,
+				Reason:   "importers using it may no longer compile",
+			})
+		}
+	}
+
+	for i := range current {
+		n := &current[i]
+		if _, ok := recordedByID[n.id()]; ok {
+			continue
+		}
+
+		change := Change{
+			New:    n,
+			Reason: "current API does not affect existing importers",
+		}
+
+		if iface, ok := interfaceOf(n.Key); ok {
+			if _, existed := recordedByID[n.Pkg+"\ttype "+iface]; existed {
+				change.Breaking = true
+				change.Reason = "types implementing the existing interface no longer satisfy it"
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].feature().id() < changes[j].feature().id()
+	})
+
+	return changes
+}
+
+// feature returns whichever side of the change exists, preferring the old.
+func (c Change) feature() *Feature {
+	if c.Old != nil {
+		return c.Old
+	}
+	return c.New
+}
+
+// interfaceOf reports whether the key describes a method of an interface
+// and returns the interface name.
+func interfaceOf(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, "type ")
+	if !ok {
+		return "", false
+	}
+
+	name, _, ok := strings.Cut(rest, " interface, ")
+	return name, ok
+}
@@ -0,0 +1,114 @@
+package apicheck
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"ultimate-go-programming/tools/analysis"
+)
+
+// snapshotFile is the snapshot checked in for the packages lesson.
+const snapshotFile = "../../language/decoupling/packages/api.txt"
+
+// TestSnapshotCurrent regenerates the snapshot of the packages lesson and
+// compares it with the checked in file byte for byte, so the snapshot can't
+// drift from the tree or depend on where the tree is loaded from.
+func TestSnapshotCurrent(t *testing.T) {
+	pkgs, err := analysis.Load("../../language/decoupling/packages/...")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	features, err := Snapshot(pkgs)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := WriteSnapshot(&got, features); err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+
+	want, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.String() != string(want) {
+		t.Errorf("snapshot is stale, regenerate with: go run ./cmd/apicheck -update\ngot:\n%s\nwant:\n%s", got.String(), want)
+	}
+
+	if changes := Compare(mustRead(t, want), features); len(changes) != 0 {
+		t.Errorf("Compare found %d changes against the snapshot: %v", len(changes), changes)
+	}
+}
+
+// TestSnapshotRoundTrip reads back what WriteSnapshot writes.
+func TestSnapshotRoundTrip(t *testing.T) {
+	features := []Feature{
+		{Pkg: "p", Key: "func New", Value: "(int) *T"},
+		{Pkg: "p", Key: "type T", Value: "struct"},
+		{Pkg: "p", Key: "type T struct, Name", Value: "string"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, features); err != nil {
+		t.Fatal(err)
+	}
+
+	got := mustRead(t, buf.Bytes())
+	if len(got) != len(features) {
+		t.Fatalf("read %d features, want %d", len(got), len(features))
+	}
+	for i := range got {
+		if got[i] != features[i] {
+			t.Errorf("feature %d = %v, want %v", i, got[i], features[i])
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	recorded := []Feature{
+		{Pkg: "p", Key: "func Gone", Value: "()"},
+		{Pkg: "p", Key: "func New", Value: "(int) *T"},
+		{Pkg: "p", Key: "type I", Value: "interface"},
+		{Pkg: "p", Key: "type I interface, Read", Value: "() int"},
+	}
+	current := []Feature{
+		{Pkg: "p", Key: "func Added", Value: "()"},
+		{Pkg: "p", Key: "func New", Value: "(int, string) *T"},
+		{Pkg: "p", Key: "type I", Value: "interface"},
+		{Pkg: "p", Key: "type I interface, Read", Value: "() int"},
+		{Pkg: "p", Key: "type I interface, Write", Value: "(int)"},
+	}
+
+	want := []string{
+		"compatible: added p, func Added ()",
+		"BREAKING: removed p, func Gone ()",
+		"BREAKING: changed p, func New: (int) *T -> (int, string) *T",
+		"BREAKING: added p, type I interface, Write (int)",
+	}
+
+	changes := Compare(recorded, current)
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes %v, want %d", len(changes), changes, len(want))
+	}
+	for i, c := range changes {
+		if !strings.HasPrefix(c.String(), want[i]+" (") {
+			t.Errorf("change %d = %q, want prefix %q", i, c, want[i])
+		}
+	}
+}
+
+// mustRead reads a snapshot from the data.
+func mustRead(t *testing.T, data []byte) []Feature {
+	t.Helper()
+
+	features, err := ReadSnapshot(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	return features
+}
@@ -0,0 +1,608 @@
+// Package deepdiff compares two values field by field and reports every
+// difference with the path that leads to it:
+//
+//	.name: "Peter Parker" -> "Spider Man"
+//	.logins: 3 -> 4
+//	+ .tags[2]: "admin"
+//	- .friends["bill"]: {name:Bill email:bill@email.com logins:0}
+//
+// Unlike reflect.DeepEqual, which only says whether two values are equal,
+// Diff says where they differ. Struct fields are compared whether they are
+// exported or not. Pointers, maps and slices are followed and a pair of them
+// already being compared, as in a cycle, is not compared again. Elements
+// inserted into or removed from a slice are reported as such instead of as a
+// change to every element after them. Comparers replace the comparison for the
+// types they are registered for, like time.Time whose values are best
+// compared with its Equal method.
+package deepdiff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"unsafe"
+)
+
+// maxLCS is the largest number of pairs of elements compared to find the
+// elements inserted into or removed from a slice. Longer slices are compared
+// index by index.
+const maxLCS = 1 << 20
+
+// Op says what happened to a value.
+type Op int
+
+// Set of operations.
+const (
+	Changed Op = iota // The value is in both but differs.
+	Added             // The value is only in b.
+	Removed           // The value is only in a.
+)
+
+// String implements the fmt.Stringer interface.
+func (op Op) String() string {
+	switch op {
+	case Changed:
+		return "changed"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return "Op(" + strconv.Itoa(int(op)) + ")"
+}
+
+// Change is a difference between the two values.
+type Change struct {
+	Path string // Path from the compared value, like .users[2].name. Empty for the value itself.
+	Op   Op
+	Old  string // The value in a, empty when Added.
+	New  string // The value in b, empty when Removed.
+}
+
+// String implements the fmt.Stringer interface.
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "."
+	}
+
+	switch c.Op {
+	case Added:
+		return "+ " + path + ": " + c.New
+	case Removed:
+		return "- " + path + ": " + c.Old
+	}
+	return path + ": " + c.Old + " -> " + c.New
+}
+
+// Comparer decides whether two values of a type are equal.
+type Comparer struct {
+	Type  reflect.Type
+	Equal func(a any, b any) bool
+}
+
+// ComparerFor constructs a Comparer for the values of type T.
+func ComparerFor[T any](equal func(a T, b T) bool) Comparer {
+	c := Comparer{
+		Type: reflect.TypeFor[T](),
+		Equal: func(a any, b any) bool {
+			return equal(a.(T), b.(T))
+		},
+	}
+	return c
+}
+
+// Options controls how values are compared.
+type Options struct {
+	IgnoreUnexported bool       // Don't compare unexported struct fields.
+	Comparers        []Comparer // Comparisons to use instead of walking the value.
+}
+
+// Diff returns the differences between a and b. A nil slice and an empty
+// slice are equal, and so are a nil map and an empty map.
+func Diff(a any, b any, opts Options) []Change {
+	d := differ{
+		opts:    opts,
+		visited: make(map[visit]bool),
+	}
+	d.diff("", addressable(reflect.ValueOf(a)), addressable(reflect.ValueOf(b)))
+	return d.changes
+}
+
+// Equal reports whether Diff finds no differences between a and b. It stops
+// looking at the first one.
+func Equal(a any, b any, opts Options) bool {
+	d := differ{
+		opts:    opts,
+		visited: make(map[visit]bool),
+		probe:   true,
+		limit:   1,
+	}
+	d.diff("", addressable(reflect.ValueOf(a)), addressable(reflect.ValueOf(b)))
+	return d.count == 0
+}
+
+// Write writes the changes one per line.
+func Write(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+
+// visit is a pair of pointers, maps or slices being compared. The lengths
+// tell apart slices of different lengths that start at the same element. A
+// differ only holds the pairs on the path to the value it is comparing, so
+// a pair met again is part of a cycle.
+type visit struct {
+	a    uintptr
+	b    uintptr
+	alen int
+	blen int
+	typ  reflect.Type
+}
+
+// differ collects the changes between two values.
+type differ struct {
+	opts    Options
+	visited map[visit]bool
+	changes []Change
+
+	// A probe only counts the changes and stops once it reaches the
+	// limit, when there is one.
+	probe bool
+	limit int
+	count int
+}
+
+// add records a change.
+func (d *differ) add(path string, op Op, a reflect.Value, b reflect.Value) {
+	d.count++
+	if d.probe {
+		return
+	}
+
+	c := Change{Path: path, Op: op}
+	if op != Added {
+		c.Old = format(a)
+	}
+	if op != Removed {
+		c.New = format(b)
+	}
+	d.changes = append(d.changes, c)
+}
+
+// equal reports whether the values are equal. Like measure it stops at
+// the first difference.
+func (d *differ) equal(a reflect.Value, b reflect.Value) bool {
+	return d.measure(a, b, 1) == 0
+}
+
+// measure returns the number of changes between the values, or limit when
+// there are at least that many and limit is not zero. The probe shares the
+// pairs being compared, so probing the elements of a cycle ends like
+// comparing them does.
+func (d *differ) measure(a reflect.Value, b reflect.Value, limit int) int {
+	probe := differ{
+		opts:    d.opts,
+		visited: d.visited,
+		probe:   true,
+		limit:   limit,
+	}
+	probe.diff("", a, b)
+	return probe.count
+}
+
+// enter marks the pair as being compared and returns its key, which the
+// caller removes once done. It reports false when the pair is already being
+// compared further up the path.
+func (d *differ) enter(a reflect.Value, b reflect.Value) (visit, bool) {
+	key := visit{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+	if a.Kind() == reflect.Slice {
+		key.alen, key.blen = a.Len(), b.Len()
+	}
+
+	if d.visited[key] {
+		return key, false
+	}
+	d.visited[key] = true
+	return key, true
+}
+
+// diff compares the values at the path.
+func (d *differ) diff(path string, a reflect.Value, b reflect.Value) {
+	if d.limit > 0 && d.count >= d.limit {
+		return
+	}
+
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return
+	case !a.IsValid() || !b.IsValid() || a.Type() != b.Type():
+		d.add(path, Changed, a, b)
+		return
+	}
+
+	for _, c := range d.opts.Comparers {
+		if c.Type != a.Type() {
+			continue
+		}
+		ea, eb := exported(a), exported(b)
+		if ea.CanInterface() && eb.CanInterface() {
+			if !c.Equal(ea.Interface(), eb.Interface()) {
+				d.add(path, Changed, a, b)
+			}
+			return
+		}
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Changed, a, b)
+			}
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			return
+		}
+		key, ok := d.enter(a, b)
+		if !ok {
+			return
+		}
+		defer delete(d.visited, key)
+
+		d.diff(path, a.Elem(), b.Elem())
+
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Changed, a, b)
+			}
+			return
+		}
+		d.diff(path, addressable(a.Elem()), addressable(b.Elem()))
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() && d.opts.IgnoreUnexported {
+				continue
+			}
+			d.diff(path+"."+f.Name, a.Field(i), b.Field(i))
+		}
+
+	case reflect.Array:
+		for i := range a.Len() {
+			d.diff(path+"["+strconv.Itoa(i)+"]", a.Index(i), b.Index(i))
+		}
+
+	case reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return
+		}
+		if a.Pointer() == b.Pointer() && a.Len() == b.Len() {
+			return
+		}
+		key, ok := d.enter(a, b)
+		if !ok {
+			return
+		}
+		defer delete(d.visited, key)
+
+		d.diffSlice(path, a, b)
+
+	case reflect.Map:
+		if a.Pointer() == b.Pointer() {
+			return
+		}
+		key, ok := d.enter(a, b)
+		if !ok {
+			return
+		}
+		defer delete(d.visited, key)
+
+		d.diffMap(path, a, b)
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			d.add(path, Changed, a, b)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		// NaN is not equal to itself but two NaNs are not a change.
+		fa, fb := a.Float(), b.Float()
+		if fa != fb && (fa == fa || fb == fb) {
+			d.add(path, Changed, a, b)
+		}
+
+	default:
+		if !a.Equal(b) {
+			d.add(path, Changed, a, b)
+		}
+	}
+}
+
+// diffSlice compares the elements of two slices. The longest common
+// subsequence of equal elements finds the elements that were inserted or
+// removed.
+func (d *differ) diffSlice(path string, a reflect.Value, b reflect.Value) {
+	n, m := a.Len(), b.Len()
+	index := func(i int) string {
+		return path + "[" + strconv.Itoa(i) + "]"
+	}
+
+	// Finding out whether the slices are equal needs no alignment.
+	if n*m > maxLCS || d.limit == 1 {
+		for i := range min(n, m) {
+			d.diff(index(i), a.Index(i), b.Index(i))
+		}
+		for i := m; i < n; i++ {
+			d.add(index(i), Removed, a.Index(i), reflect.Value{})
+		}
+		for i := n; i < m; i++ {
+			d.add(index(i), Added, reflect.Value{}, b.Index(i))
+		}
+		return
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	eq := make([][]bool, n)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		eq[i] = make([]bool, m)
+		for j := m - 1; j >= 0; j-- {
+			eq[i][j] = d.equal(a.Index(i), b.Index(j))
+			if eq[i][j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Walk the table collecting runs of removed and added elements between
+	// the equal ones.
+	var removed, added []int
+	flush := func() {
+		d.diffRun(index, a, b, removed, added)
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && eq[i][j]:
+			flush()
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+}
+
+// diffRun reports a run of elements removed from a and added to b. A removed
+// element is paired with an added one and compared field by field when that
+// takes fewer changes than reporting both whole, so an element that was
+// edited reads as the fields that changed while an element that was
+// inserted next to it still reads as added.
+func (d *differ) diffRun(index func(int) string, a reflect.Value, b reflect.Value, removed []int, added []int) {
+	n, m := len(removed), len(added)
+	if n == 0 && m == 0 {
+		return
+	}
+
+	// The cost of reporting an element whole is the number of changes from
+	// its zero value, the cost of a pair is the number of changes between
+	// them. Every cost is worked out once.
+	zero := reflect.Zero(a.Type().Elem())
+	costRemoved := make([]int, n)
+	for i, r := range removed {
+		costRemoved[i] = max(d.measure(a.Index(r), zero, 0), 1)
+	}
+	costAdded := make([]int, m)
+	for j, ad := range added {
+		costAdded[j] = max(d.measure(zero, b.Index(ad), 0), 1)
+	}
+	costPair := make([][]int, n)
+	for i, r := range removed {
+		costPair[i] = make([]int, m)
+		for j, ad := range added {
+			costPair[i][j] = d.measure(a.Index(r), b.Index(ad), 0)
+		}
+	}
+
+	// cost[i][j] is the cheapest way to report removed[i:] and added[j:].
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+	}
+	for i := n; i >= 0; i-- {
+		for j := m; j >= 0; j-- {
+			switch {
+			case i == n && j == m:
+				continue
+			case i == n:
+				cost[i][j] = cost[i][j+1] + costAdded[j]
+			case j == m:
+				cost[i][j] = cost[i+1][j] + costRemoved[i]
+			default:
+				pair := cost[i+1][j+1] + costPair[i][j]
+				cost[i][j] = min(pair, cost[i+1][j]+costRemoved[i], cost[i][j+1]+costAdded[j])
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < n || j < m; {
+		switch {
+		case i < n && j < m && cost[i][j] == cost[i+1][j+1]+costPair[i][j]:
+			d.diff(index(added[j]), a.Index(removed[i]), b.Index(added[j]))
+			i++
+			j++
+		case i < n && (j == m || cost[i][j] == cost[i+1][j]+costRemoved[i]):
+			d.add(index(removed[i]), Removed, a.Index(removed[i]), reflect.Value{})
+			i++
+		default:
+			d.add(index(added[j]), Added, reflect.Value{}, b.Index(added[j]))
+			j++
+		}
+	}
+}
+
+// diffMap compares the entries of two maps in the order of their keys.
+func (d *differ) diffMap(path string, a reflect.Value, b reflect.Value) {
+	keys := a.MapKeys()
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keyString(keys[i]) < keyString(keys[j])
+	})
+
+	for _, k := range keys {
+		p := path + "[" + keyString(k) + "]"
+		va, vb := a.MapIndex(k), b.MapIndex(k)
+		switch {
+		case !va.IsValid():
+			d.add(p, Added, va, vb)
+		case !vb.IsValid():
+			d.add(p, Removed, va, vb)
+		default:
+			d.diff(p, addressable(va), addressable(vb))
+		}
+	}
+}
+
+// =============================================================================
+
+// addressable returns an addressable copy of the value so the unexported
+// fields it holds can be read by a Comparer.
+func addressable(v reflect.Value) reflect.Value {
+	if !v.IsValid() || v.CanAddr() || !v.CanInterface() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// exported returns the value without the read-only flag that values reached
+// through unexported fields carry, when it is addressable.
+func exported(v reflect.Value) reflect.Value {
+	if v.CanInterface() || !v.CanAddr() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// format returns the value as it is shown in a change. A value that holds
+// itself through a map or a slice, which the fmt package would print
+// forever, is shown as its type.
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<invalid>"
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return "&" + format(v.Elem())
+	case reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return "nil"
+		}
+	}
+
+	if holdsItself(v, make(map[visit]bool)) {
+		t := v.Type()
+		if v.Kind() == reflect.Interface {
+			t = v.Elem().Type()
+		}
+		return t.String() + "{…}"
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// holdsItself reports whether the value holds a map or a slice that holds
+// itself. It follows what the fmt package prints, which shows the pointers
+// it finds inside a value as addresses. The path holds the maps and slices
+// that lead to the value.
+func holdsItself(v reflect.Value, path map[visit]bool) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return !v.IsNil() && holdsItself(v.Elem(), path)
+
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if holdsItself(v.Field(i), path) {
+				return true
+			}
+		}
+
+	case reflect.Array:
+		for i := range v.Len() {
+			if holdsItself(v.Index(i), path) {
+				return true
+			}
+		}
+
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return false
+		}
+		key := visit{a: v.Pointer(), alen: v.Len(), typ: v.Type()}
+		if path[key] {
+			return true
+		}
+		path[key] = true
+		defer delete(path, key)
+
+		if v.Kind() == reflect.Slice {
+			for i := range v.Len() {
+				if holdsItself(v.Index(i), path) {
+					return true
+				}
+			}
+			return false
+		}
+		for it := v.MapRange(); it.Next(); {
+			if holdsItself(it.Key(), path) || holdsItself(it.Value(), path) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// keyString returns the map key as it is shown in a path.
+func keyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return strconv.Quote(k.String())
+	}
+	return fmt.Sprintf("%v", k)
+}
@@ -0,0 +1,262 @@
+package deepdiff
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type user struct {
+	name   string
+	email  string
+	logins int
+	tags   []string
+}
+
+type node struct {
+	value int
+	next  *node
+}
+
+func TestDiff(t *testing.T) {
+	bill := user{name: "Bill", email: "bill@email.com"}
+
+	tests := []struct {
+		name string
+		a    any
+		b    any
+		opts Options
+		want []string
+	}{
+		{
+			name: "equal",
+			a:    bill,
+			b:    bill,
+		},
+		{
+			name: "fields",
+			a:    user{name: "Peter Parker", logins: 3},
+			b:    user{name: "Spider Man", logins: 4},
+			want: []string{
+				`.name: "Peter Parker" -> "Spider Man"`,
+				`.logins: 3 -> 4`,
+			},
+		},
+		{
+			name: "ignore unexported",
+			a:    user{name: "Peter Parker"},
+			b:    user{name: "Spider Man"},
+			opts: Options{IgnoreUnexported: true},
+		},
+		{
+			name: "top level",
+			a:    1,
+			b:    2,
+			want: []string{`.: 1 -> 2`},
+		},
+		{
+			name: "types",
+			a:    1,
+			b:    "1",
+			want: []string{`.: 1 -> "1"`},
+		},
+		{
+			name: "inserted",
+			a:    []string{"a", "b", "c"},
+			b:    []string{"a", "x", "b", "c"},
+			want: []string{`+ [1]: "x"`},
+		},
+		{
+			name: "removed",
+			a:    []string{"a", "b", "c"},
+			b:    []string{"a", "c"},
+			want: []string{`- [1]: "b"`},
+		},
+		{
+			name: "edited next to inserted",
+			a:    []user{{name: "Bill", logins: 1}},
+			b:    []user{{name: "Ann"}, {name: "Bill", logins: 2}},
+			want: []string{
+				`+ [0]: {name:Ann email: logins:0 tags:[]}`,
+				`[1].logins: 1 -> 2`,
+			},
+		},
+		{
+			name: "nil and empty",
+			a:    [2]any{[]int(nil), map[int]int(nil)},
+			b:    [2]any{[]int{}, map[int]int{}},
+		},
+		{
+			name: "map",
+			a:    map[string]int{"a": 1, "b": 2},
+			b:    map[string]int{"b": 3, "c": 4},
+			want: []string{
+				`- ["a"]: 1`,
+				`["b"]: 2 -> 3`,
+				`+ ["c"]: 4`,
+			},
+		},
+		{
+			name: "pointers",
+			a:    &node{value: 1, next: &node{value: 2}},
+			b:    &node{value: 1, next: &node{value: 3}},
+			want: []string{`.next.value: 2 -> 3`},
+		},
+		{
+			name: "nil pointer",
+			a:    &node{value: 1},
+			b:    &node{value: 1, next: &node{value: 2}},
+			want: []string{`.next: nil -> &{value:2 next:<nil>}`},
+		},
+		{
+			name: "NaN",
+			a:    []float64{1, math.NaN()},
+			b:    []float64{2, math.NaN()},
+			want: []string{`[0]: 1 -> 2`},
+		},
+		{
+			name: "comparer",
+			a:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			b:    time.Date(2024, 1, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600)),
+			opts: Options{Comparers: []Comparer{ComparerFor(time.Time.Equal)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lines(Diff(tt.a, tt.b, tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff:\ngot  %q\nwant %q", got, tt.want)
+			}
+			if eq := Equal(tt.a, tt.b, tt.opts); eq != (len(tt.want) == 0) {
+				t.Errorf("Equal = %v with %d changes", eq, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestDiffCycles(t *testing.T) {
+	newRing := func(values ...int) *node {
+		first := &node{value: values[0]}
+		last := first
+		for _, v := range values[1:] {
+			last.next = &node{value: v}
+			last = last.next
+		}
+		last.next = first
+		return first
+	}
+
+	newMap := func(v int) map[string]any {
+		m := map[string]any{"v": v}
+		m["self"] = m
+		return m
+	}
+
+	newSlice := func(v int) []any {
+		s := make([]any, 2)
+		s[0], s[1] = v, s
+		return s
+	}
+
+	tests := []struct {
+		name string
+		a    any
+		b    any
+		want []string
+	}{
+		{
+			name: "pointers",
+			a:    newRing(1, 2, 3),
+			b:    newRing(1, 2, 4),
+			want: []string{`.next.next.value: 3 -> 4`},
+		},
+		{
+			name: "maps",
+			a:    newMap(1),
+			b:    newMap(2),
+			want: []string{`["v"]: 1 -> 2`},
+		},
+		{
+			name: "slices",
+			a:    newSlice(1),
+			b:    newSlice(2),
+			want: []string{`[0]: 1 -> 2`},
+		},
+		{
+			name: "equal maps",
+			a:    newMap(1),
+			b:    newMap(1),
+		},
+		{
+			name: "removed map",
+			a:    []any{newMap(1)},
+			b:    []any{},
+			want: []string{`- [0]: map[string]interface {}{…}`},
+		},
+		{
+			name: "removed slice",
+			a:    map[string]any{"s": newSlice(1)},
+			b:    map[string]any{},
+			want: []string{`- ["s"]: []interface {}{…}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lines(Diff(tt.a, tt.b, Options{}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	shared := []int{1, 2}
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"string", "go", `"go"`},
+		{"pointer", &user{name: "Bill"}, `&{name:Bill email: logins:0 tags:[]}`},
+		{"nil slice", []int(nil), `nil`},
+		{"shared", [][]int{shared, shared}, `[[1 2] [1 2]]`},
+	}
+
+	for _, tt := range tests {
+		if got := format(reflect.ValueOf(tt.v)); got != tt.want {
+			t.Errorf("%s: format = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestDiffLong makes sure every pair of elements of a long slice isn't
+// diffed more than once.
+func TestDiffLong(t *testing.T) {
+	a := make([]user, 300)
+	b := make([]user, 300)
+	for i := range a {
+		a[i] = user{name: strings.Repeat("x", i%7), logins: i}
+		b[i] = user{name: strings.Repeat("x", i%7), logins: i + i%2}
+	}
+
+	if got := len(Diff(a, b, Options{})); got != 150 {
+		t.Errorf("got %d changes, want 150", got)
+	}
+}
+
+func lines(changes []Change) []string {
+	if len(changes) == 0 {
+		return nil
+	}
+	list := make([]string, 0, len(changes))
+	for _, c := range changes {
+		list = append(list, c.String())
+	}
+	return list
+}
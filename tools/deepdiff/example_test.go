@@ -0,0 +1,95 @@
+package deepdiff_test
+
+import (
+	"fmt"
+	"os"
+
+	"ultimate-go-programming/tools/deepdiff"
+)
+
+type person struct {
+	name   string
+	email  string
+	logins int
+}
+
+// Show what changed in a value shared with a function, with no need to
+// eyeball two printed values.
+func ExampleDiff() {
+	// changeName changes the value the pointer points to.
+	changeName := func(u *person) {
+		u.name = "Spider Man"
+		u.logins++
+	}
+
+	p := person{
+		name:   "Peter Parker",
+		email:  "peter@spider.com",
+		logins: 3,
+	}
+
+	// Keep a copy of the value before sharing it.
+	before := p
+	changeName(&p)
+
+	deepdiff.Write(os.Stdout, deepdiff.Diff(before, p, deepdiff.Options{}))
+	fmt.Println()
+
+	// Inserting into a slice shows up as one element added, not as a
+	// change to every element after it.
+	team := []person{
+		{name: "Bill", email: "bill@email.com"},
+		{name: "Lisa", email: "lisa@email.com"},
+	}
+	grown := []person{
+		{name: "Bill", email: "bill@email.com"},
+		p,
+		{name: "Lisa", email: "lisa@email.org"},
+	}
+
+	deepdiff.Write(os.Stdout, deepdiff.Diff(team, grown, deepdiff.Options{}))
+
+	// Output:
+	// .name: "Peter Parker" -> "Spider Man"
+	// .logins: 3 -> 4
+	//
+	// + [1]: {name:Spider Man email:peter@spider.com logins:4}
+	// [2].email: "lisa@email.com" -> "lisa@email.org"
+}
+
+// A value of an anonymous struct type assigned to a named struct type is a
+// copy with the same fields.
+func ExampleEqual() {
+	type example struct {
+		flag    bool
+		counter int16
+		pi      float32
+	}
+
+	e := struct {
+		flag    bool
+		counter int16
+		pi      float32
+	}{
+		flag:    true,
+		counter: 10,
+		pi:      3.141592,
+	}
+
+	// Assign the value of the unnamed struct type to the named struct
+	// type value.
+	var ex example = e
+
+	// The types differ, so convert e to compare the fields.
+	fmt.Println("equal:", deepdiff.Equal(ex, example(e), deepdiff.Options{}))
+
+	// Changing the copy doesn't change e.
+	ex.counter++
+	ex.pi = 3.14
+	deepdiff.Write(os.Stdout, deepdiff.Diff(example(e), ex, deepdiff.Options{}))
+
+	// Output:
+	// equal: true
+	// .counter: 10 -> 11
+	// .pi: 3.141592 -> 3.14
+}
@@ -0,0 +1,149 @@
+// Package growth models how the built-in function append grows the capacity
+// of a slice. It mirrors the policy found in the runtime's growslice function
+// so tools can estimate the cost of appending without running the code.
+//
+// The policy has two parts. First a new capacity is picked: double the old
+// capacity for small slices, then transition to growing by roughly 25% once
+// the capacity reaches 256 elements. Then the memory request is rounded up
+// to the allocator's size class, which is why capacities like 5 or 341
+// appear in SlicesExample4's output. Measure records the sequence the
+// running runtime actually produces so the model can be checked against it.
+package growth
+
+// threshold is the capacity where append stops doubling.
+const threshold = 256
+
+// Allocator constants for 64 bit platforms.
+const (
+	maxSmallSize           = 32768
+	pageSize               = 8192
+	mallocHeaderSize       = 8
+	minSizeForMallocHeader = 512
+)
+
+// sizeClasses are the object sizes the allocator hands out for small
+// objects. A request is rounded up to the next class.
+var sizeClasses = [...]uintptr{
+	8, 16, 24, 32, 48, 64, 80, 96, 112, 128, 144, 160, 176, 192, 208, 224,
+	240, 256, 288, 320, 352, 384, 416, 448, 480, 512, 576, 640, 704, 768, 896,
+	1024, 1152, 1280, 1408, 1536, 1792, 2048, 2304, 2688, 3072, 3200, 3456,
+	4096, 4864, 5376, 6144, 6528, 6784, 6912, 8192, 9472, 9728, 10240, 10880,
+	12288, 13568, 14336, 16384, 18432, 19072, 20480, 21760, 24576, 27264,
+	28672, 32768,
+}
+
+// Step describes a single time append had to grow the backing array.
+type Step struct {
+	Len     int     // Number of elements after the append that grew.
+	OldCap  int     // Capacity before the growth.
+	Cap     int     // Capacity after the growth.
+	Bytes   uintptr // Size of the new backing array.
+	Copied  uintptr // Bytes copied from the old backing array.
+	Percent float64 // Capacity growth over the old capacity.
+}
+
+// RoundUpSize returns the size of the memory block the allocator returns
+// for a request of the specified size. Blocks holding pointers and larger
+// than 512 bytes carry an 8 byte header that is not usable by the slice.
+func RoundUpSize(size uintptr, noscan bool) uintptr {
+	if size == 0 {
+		return 0
+	}
+
+	if size <= maxSmallSize-mallocHeaderSize {
+		req := size
+		if !noscan && req > minSizeForMallocHeader {
+			req += mallocHeaderSize
+		}
+
+		for _, class := range sizeClasses {
+			if class >= req {
+				return class - (req - size)
+			}
+		}
+	}
+
+	return (size + pageSize - 1) &^ (pageSize - 1)
+}
+
+// NextCap returns the capacity append picks when a slice with the
+// specified capacity needs to hold newLen elements of elemSize bytes.
+func NextCap(oldCap int, newLen int, elemSize uintptr, hasPointers bool) int {
+	newCap := oldCap
+	doubleCap := newCap + newCap
+
+	switch {
+	case newLen > doubleCap:
+		newCap = newLen
+
+	case oldCap < threshold:
+		newCap = doubleCap
+
+	default:
+		for newCap < newLen {
+			newCap += (newCap + 3*threshold) >> 2
+		}
+	}
+
+	if elemSize == 0 {
+		return newLen
+	}
+
+	mem := RoundUpSize(uintptr(newCap)*elemSize, !hasPointers)
+	return int(mem / elemSize)
+}
+
+// Steps returns every growth that happens when n elements of elemSize bytes
+// are appended one at a time to a nil slice. Zero sized elements never
+// allocate so nil is returned for them.
+func Steps(n int, elemSize uintptr, hasPointers bool) []Step {
+	if elemSize == 0 {
+		return nil
+	}
+
+	var steps []Step
+	var capacity int
+
+	for length := 1; length <= n; length++ {
+		if length <= capacity {
+			continue
+		}
+
+		newCap := NextCap(capacity, length, elemSize, hasPointers)
+
+		step := Step{
+			Len:    length,
+			OldCap: capacity,
+			Cap:    newCap,
+			Bytes:  uintptr(newCap) * elemSize,
+			Copied: uintptr(length-1) * elemSize,
+		}
+		if capacity > 0 {
+			step.Percent = float64(newCap-capacity) / float64(capacity) * 100
+		}
+
+		steps = append(steps, step)
+		capacity = newCap
+	}
+
+	return steps
+}
+
+// Cost summarizes the work append performs to reach n elements.
+type Cost struct {
+	Allocations int     // Number of backing arrays allocated.
+	Copied      uintptr // Total bytes copied between backing arrays.
+	Allocated   uintptr // Total bytes allocated for backing arrays.
+}
+
+// Estimate returns the cost of appending n elements one at a time to
+// a nil slice.
+func Estimate(n int, elemSize uintptr, hasPointers bool) Cost {
+	var c Cost
+	for _, step := range Steps(n, elemSize, hasPointers) {
+		c.Allocations++
+		c.Copied += step.Copied
+		c.Allocated += step.Bytes
+	}
+	return c
+}
@@ -0,0 +1,137 @@
+package growth
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestModel checks the model against the capacities the running runtime
+// produces for a range of element sizes and layouts.
+func TestModel(t *testing.T) {
+	sizes := []uintptr{1, 2, 3, 4, 5, 7, 8, 12, 16, 24, 32, 40, 48, 56, 64, 72, 96, 100, 128, 200, 256, 512, 1000, 1024, 4096}
+
+	for _, size := range sizes {
+		for _, hasPointers := range []bool{false, true} {
+			if _, err := ElemType(size, hasPointers); err != nil {
+				continue
+			}
+
+			t.Run(fmt.Sprintf("%d/%v", size, hasPointers), func(t *testing.T) {
+				n := 20000
+				if size >= 512 {
+					n = 2000
+				}
+
+				list, err := Compare(n, size, hasPointers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, c := range list {
+					if !c.Match {
+						t.Fatalf("at len %d the runtime grew cap %d to %d, the model %d to %d",
+							c.Measured.Len, c.Measured.OldCap, c.Measured.Cap, c.Model.OldCap, c.Model.Cap)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestNextCap(t *testing.T) {
+	tests := []struct {
+		oldCap      int
+		newLen      int
+		elemSize    uintptr
+		hasPointers bool
+		want        int
+	}{
+		{0, 1, 8, false, 1},
+		{0, 1, 1, false, 8},
+		{0, 5, 8, false, 6},
+		{4, 5, 8, false, 8},
+		{5, 6, 16, false, 10},
+		{256, 257, 8, false, 512},
+		{512, 513, 8, false, 848},
+		{4, 100, 8, false, 112},
+		{10, 11, 0, false, 11},
+		{64, 65, 16, false, 128},
+		{64, 65, 16, true, 143},
+	}
+
+	for _, tt := range tests {
+		got := NextCap(tt.oldCap, tt.newLen, tt.elemSize, tt.hasPointers)
+		if got != tt.want {
+			t.Errorf("NextCap(%d, %d, %d, %v) = %d, want %d", tt.oldCap, tt.newLen, tt.elemSize, tt.hasPointers, got, tt.want)
+		}
+	}
+}
+
+func TestRoundUpSize(t *testing.T) {
+	tests := []struct {
+		size   uintptr
+		noscan bool
+		want   uintptr
+	}{
+		{0, true, 0},
+		{1, true, 8},
+		{8, true, 8},
+		{9, true, 16},
+		{33, true, 48},
+		{513, true, 576},
+		{513, false, 568},
+		{504, false, 512},
+		{32768, true, 32768},
+		{32761, false, 32768},
+		{32769, true, 40960},
+		{40000, true, 40960},
+	}
+
+	for _, tt := range tests {
+		if got := RoundUpSize(tt.size, tt.noscan); got != tt.want {
+			t.Errorf("RoundUpSize(%d, %v) = %d, want %d", tt.size, tt.noscan, got, tt.want)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	steps := Steps(1000, 8, false)
+
+	var want Cost
+	for _, s := range steps {
+		want.Allocations++
+		want.Copied += s.Copied
+		want.Allocated += s.Bytes
+	}
+	if got := Estimate(1000, 8, false); got != want {
+		t.Errorf("Estimate = %+v, want %+v", got, want)
+	}
+
+	if got := Estimate(1000, 0, false); got != (Cost{}) {
+		t.Errorf("Estimate of zero sized elements = %+v, want none", got)
+	}
+	if steps := Steps(0, 8, false); steps != nil {
+		t.Errorf("Steps of no elements = %v, want none", steps)
+	}
+}
+
+func TestElemType(t *testing.T) {
+	for _, size := range []uintptr{1, 3, 8, 24, 100} {
+		typ, err := ElemType(size, false)
+		if err != nil || typ.Size() != size {
+			t.Errorf("ElemType(%d, false) = %v, %v", size, typ, err)
+		}
+	}
+
+	for _, size := range []uintptr{8, 16, 64} {
+		typ, err := ElemType(size, true)
+		if err != nil || typ.Size() != size {
+			t.Errorf("ElemType(%d, true) = %v, %v", size, typ, err)
+		}
+	}
+
+	for _, size := range []uintptr{0, 1, 12} {
+		if _, err := ElemType(size, true); err == nil {
+			t.Errorf("ElemType(%d, true) took a size that can't hold a pointer", size)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package growth
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// ptrSize is the size of a pointer on the running platform.
+const ptrSize = unsafe.Sizeof(uintptr(0))
+
+// Measure appends n elements of the specified size one at a time to a nil
+// slice and records every time the runtime had to grow the backing array.
+//
+// The element type is built with reflection so any size can be measured.
+// Pointer free elements are byte arrays. Elements with pointers start with
+// a pointer field and are padded with bytes, so their size must be a
+// multiple of the pointer size. reflect.Append grows slices with the same
+// runtime function the append built-in uses.
+func Measure(n int, elemSize uintptr, hasPointers bool) ([]Step, error) {
+	typ, err := ElemType(elemSize, hasPointers)
+	if err != nil {
+		return nil, err
+	}
+
+	if elemSize == 0 {
+		return nil, nil
+	}
+
+	var steps []Step
+
+	zero := reflect.Zero(typ)
+	s := reflect.Zero(reflect.SliceOf(typ))
+
+	for length := 1; length <= n; length++ {
+		oldCap := s.Cap()
+		s = reflect.Append(s, zero)
+
+		if s.Cap() == oldCap {
+			continue
+		}
+
+		step := Step{
+			Len:    length,
+			OldCap: oldCap,
+			Cap:    s.Cap(),
+			Bytes:  uintptr(s.Cap()) * elemSize,
+			Copied: uintptr(length-1) * elemSize,
+		}
+		if oldCap > 0 {
+			step.Percent = float64(s.Cap()-oldCap) / float64(oldCap) * 100
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// ElemType returns a type of the specified size that either contains
+// pointers or is pointer free.
+func ElemType(size uintptr, hasPointers bool) (reflect.Type, error) {
+	byteType := reflect.TypeFor[byte]()
+
+	if !hasPointers {
+		return reflect.ArrayOf(int(size), byteType), nil
+	}
+
+	if size < ptrSize || size%ptrSize != 0 {
+		return nil, fmt.Errorf("element size %d with pointers must be a multiple of %d", size, ptrSize)
+	}
+
+	// A struct ending in a zero sized field is padded by the compiler,
+	// so a pointer sized element is the pointer itself.
+	if size == ptrSize {
+		return reflect.PointerTo(byteType), nil
+	}
+
+	fields := []reflect.StructField{
+		{Name: "P", Type: reflect.PointerTo(byteType)},
+		{Name: "Pad", Type: reflect.ArrayOf(int(size-ptrSize), byteType)},
+	}
+
+	return reflect.StructOf(fields), nil
+}
+
+// Comparison pairs a measured growth with the growth the model predicts.
+type Comparison struct {
+	Measured Step
+	Model    Step
+	Match    bool
+}
+
+// Compare measures the growth sequence and lines it up step by step with
+// the model. The model is what explains the odd looking capacities: the
+// requested capacity is rounded up to the allocator's size class.
+func Compare(n int, elemSize uintptr, hasPointers bool) ([]Comparison, error) {
+	measured, err := Measure(n, elemSize, hasPointers)
+	if err != nil {
+		return nil, err
+	}
+	model := Steps(n, elemSize, hasPointers)
+
+	list := make([]Comparison, 0, max(len(measured), len(model)))
+	for i := range max(len(measured), len(model)) {
+		var c Comparison
+		if i < len(measured) {
+			c.Measured = measured[i]
+		}
+		if i < len(model) {
+			c.Model = model[i]
+		}
+		c.Match = c.Measured.Len == c.Model.Len && c.Measured.Cap == c.Model.Cap
+		list = append(list, c)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,337 @@
+// Package hexdump writes byte buffers as offset, hex and ASCII columns with
+// annotations drawn underneath the bytes they describe.
+//
+// A buffer like the 100 byte slice retrieve reads into holds the data that
+// was read followed by an unused tail, and printing it with %v hides where
+// one ends and the other starts. A Dump marks the unused tail, the start of
+// every rune and any labeled ranges:
+//
+//	00000000  e4 b8 96 e7 95 8c 20 6d  65 61 6e 73 00 00 00 00  |...... means....|
+//	          ^  -  -  ^  -  -  ^  ^   ^  ^  ^  ^               runes
+//	          [==================================]              data
+//	                                               [=========]  unused
+//
+// Diff writes two buffers side by side and marks the bytes that differ.
+package hexdump
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultWidth is the number of bytes on each line of a dump.
+const defaultWidth = 16
+
+// Label names a range of bytes.
+type Label struct {
+	Start int
+	End   int // One past the last byte of the range.
+	Text  string
+}
+
+// Dump describes how to write a buffer.
+type Dump struct {
+	Data   []byte
+	Width  int     // Bytes per line. Zero means 16.
+	Used   int     // Length of the valid data. Bytes past it are marked unused. Negative means all of them are valid.
+	Runes  bool    // Mark where every rune starts and flag invalid UTF-8.
+	Labels []Label // Ranges to mark underneath the bytes.
+}
+
+// New constructs a Dump of the whole buffer.
+func New(data []byte) *Dump {
+	d := Dump{
+		Data: data,
+		Used: -1,
+	}
+	return &d
+}
+
+// Label adds a labeled range of bytes.
+func (d *Dump) Label(start int, end int, text string) *Dump {
+	d.Labels = append(d.Labels, Label{start, end, text})
+	return d
+}
+
+// String returns the dump as text.
+func (d *Dump) String() string {
+	var b strings.Builder
+	d.Write(&b)
+	return b.String()
+}
+
+// Write writes the dump. A summary line comes first when part of the
+// buffer is unused.
+func (d *Dump) Write(w io.Writer) error {
+	width := d.width()
+	labels := d.labels()
+
+	var runes []byte
+	if d.Runes {
+		runes = runeMarks(d.data())
+	}
+
+	if d.Used >= 0 {
+		used := min(d.Used, len(d.Data))
+		if _, err := fmt.Fprintf(w, "%d bytes: %d used, %d unused\n", len(d.Data), used, len(d.Data)-used); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(d.Data); start += width {
+		end := min(start+width, len(d.Data))
+		line := d.Data[start:end]
+
+		if _, err := fmt.Fprintf(w, "%08x  %s  |%s|\n", start, hexColumn(line, width), asciiColumn(line)); err != nil {
+			return err
+		}
+
+		if runes != nil && start < len(runes) {
+			marks := runes[start:min(end, len(runes))]
+			if err := writeMarks(w, width, func(i int) string {
+				if i >= len(marks) {
+					return ""
+				}
+				return markText(marks[i])
+			}, "runes"); err != nil {
+				return err
+			}
+		}
+
+		for _, l := range labels {
+			if l.End <= start || l.Start >= end {
+				continue
+			}
+			if err := writeMarks(w, width, func(i int) string {
+				return rangeMark(start+i, l)
+			}, l.Text); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// width returns the number of bytes per line.
+func (d *Dump) width() int {
+	if d.Width <= 0 {
+		return defaultWidth
+	}
+	return d.Width
+}
+
+// data returns the valid part of the buffer.
+func (d *Dump) data() []byte {
+	if d.Used < 0 {
+		return d.Data
+	}
+	return d.Data[:min(d.Used, len(d.Data))]
+}
+
+// labels returns the labels to draw, with the valid data and the unused
+// tail added when Used is set.
+func (d *Dump) labels() []Label {
+	if d.Used < 0 || d.Used >= len(d.Data) {
+		return d.Labels
+	}
+
+	labels := append([]Label(nil), d.Labels...)
+	if d.Used > 0 {
+		labels = append(labels, Label{0, d.Used, "data"})
+	}
+	return append(labels, Label{d.Used, len(d.Data), "unused"})
+}
+
+// =============================================================================
+
+// Set of marks for the bytes of a rune.
+const (
+	runeStart = iota + 1
+	runeCont
+	runeInvalid
+)
+
+// runeMarks returns a mark for every byte: the first byte of a rune, a
+// continuation byte or a byte that is not valid UTF-8.
+func runeMarks(b []byte) []byte {
+	marks := make([]byte, len(b))
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size <= 1 {
+			marks[i] = runeInvalid
+			i++
+			continue
+		}
+
+		marks[i] = runeStart
+		for j := 1; j < size; j++ {
+			marks[i+j] = runeCont
+		}
+		i += size
+	}
+	return marks
+}
+
+// markText returns what is drawn under a byte for its rune mark.
+func markText(mark byte) string {
+	switch mark {
+	case runeStart:
+		return "^"
+	case runeCont:
+		return "-"
+	case runeInvalid:
+		return "!!"
+	}
+	return ""
+}
+
+// rangeMark returns what is drawn under the byte at offset for the label.
+func rangeMark(offset int, l Label) string {
+	switch {
+	case offset < l.Start || offset >= l.End:
+		return ""
+	case l.End-l.Start == 1:
+		return "[]"
+	case offset == l.Start:
+		return "[="
+	case offset == l.End-1:
+		return "=]"
+	}
+	return "=="
+}
+
+// writeMarks writes a line with a mark under every byte of the hex column
+// followed by the text. The space between two marked bytes is filled so a
+// range reads as a single bar.
+func writeMarks(w io.Writer, width int, mark func(i int) string, text string) error {
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", 10))
+
+	for i := range width {
+		m := mark(i)
+		b.WriteString(m)
+		b.WriteString(strings.Repeat(" ", 2-len(m)))
+
+		if i == width-1 {
+			break
+		}
+
+		gap := " "
+		if i%8 == 7 {
+			gap = "  "
+		}
+		if len(m) == 2 && m[1] == '=' && mark(i+1) != "" {
+			gap = strings.Repeat("=", len(gap))
+		}
+		b.WriteString(gap)
+	}
+
+	_, err := fmt.Fprintf(w, "%s  %s\n", b.String(), text)
+	return err
+}
+
+// =============================================================================
+
+// Diff writes the two buffers side by side with a line underneath every
+// line that has differences marking the bytes that differ. Bytes past the
+// end of the shorter buffer count as different. Width is the number of
+// bytes of each buffer on a line; zero means 8.
+func Diff(w io.Writer, a []byte, b []byte, width int) error {
+	if width <= 0 {
+		width = 8
+	}
+
+	var diffs int
+	first := -1
+
+	for start := 0; start < max(len(a), len(b)); start += width {
+		la := a[min(start, len(a)):min(start+width, len(a))]
+		lb := b[min(start, len(b)):min(start+width, len(b))]
+
+		if _, err := fmt.Fprintf(w, "%08x  %s  |%-*s|   %s  |%-*s|\n", start,
+			hexColumn(la, width), width, asciiColumn(la),
+			hexColumn(lb, width), width, asciiColumn(lb)); err != nil {
+			return err
+		}
+
+		var marks strings.Builder
+		var differs bool
+		for i := range width {
+			if i > 0 {
+				marks.WriteByte(' ')
+				if i%8 == 0 {
+					marks.WriteByte(' ')
+				}
+			}
+
+			inA, inB := i < len(la), i < len(lb)
+			if (inA || inB) && (!inA || !inB || la[i] != lb[i]) {
+				marks.WriteString("^^")
+				differs = true
+				diffs++
+				if first < 0 {
+					first = start + i
+				}
+				continue
+			}
+			marks.WriteString("  ")
+		}
+
+		if !differs {
+			continue
+		}
+
+		// The same marks go under both buffers.
+		half := marks.String()
+		gap := strings.Repeat(" ", width+7)
+		line := strings.TrimRight(strings.Repeat(" ", 10)+half+gap+half, " ")
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	if diffs == 0 {
+		_, err := fmt.Fprintf(w, "identical: %d bytes\n", len(a))
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "a: %d bytes, b: %d bytes, %d bytes differ, first at offset %d (%#x)\n",
+		len(a), len(b), diffs, first, first)
+	return err
+}
+
+// hexColumn formats the bytes as hex, padding short lines to the width. An
+// extra space separates every 8 bytes.
+func hexColumn(line []byte, width int) string {
+	var b strings.Builder
+	for i := range width {
+		if i > 0 {
+			b.WriteByte(' ')
+			if i%8 == 0 {
+				b.WriteByte(' ')
+			}
+		}
+		if i < len(line) {
+			fmt.Fprintf(&b, "%02x", line[i])
+		} else {
+			b.WriteString("  ")
+		}
+	}
+	return b.String()
+}
+
+// asciiColumn formats the bytes as printable ASCII, using a dot for the
+// rest.
+func asciiColumn(line []byte) string {
+	b := bytes.Clone(line)
+	for i, c := range b {
+		if c < 0x20 || c > 0x7E {
+			b[i] = '.'
+		}
+	}
+	return string(b)
+}
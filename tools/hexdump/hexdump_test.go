@@ -0,0 +1,171 @@
+package hexdump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		dump func() *Dump
+		want string
+	}{
+		{
+			name: "used and runes",
+			dump: func() *Dump {
+				buf := make([]byte, 20)
+				d := New(buf)
+				d.Used = copy(buf, "世界 means")
+				d.Runes = true
+				return d
+			},
+			want: `20 bytes: 12 used, 8 unused
+00000000  e4 b8 96 e7 95 8c 20 6d  65 61 6e 73 00 00 00 00  |...... means....|
+          ^  -  -  ^  -  -  ^  ^   ^  ^  ^  ^               runes
+          [==================================]              data
+                                               [==========  unused
+00000010  00 00 00 00                                       |....|
+          ==========]                                       unused
+`,
+		},
+		{
+			name: "invalid runes and labels",
+			dump: func() *Dump {
+				d := New([]byte("ab\xffc\xe4\xb8"))
+				d.Runes = true
+				return d.Label(1, 2, "one").Label(0, 6, "all")
+			},
+			want: `00000000  61 62 ff 63 e4 b8                                 |ab.c..|
+          ^  ^  !! ^  !! !!                                 runes
+             []                                             one
+          [===============]                                 all
+`,
+		},
+		{
+			name: "label across lines",
+			dump: func() *Dump {
+				d := New([]byte("0123456789"))
+				d.Width = 4
+				return d.Label(2, 7, "wrap")
+			},
+			want: `00000000  30 31 32 33  |0123|
+                [====  wrap
+00000004  34 35 36 37  |4567|
+          =======]     wrap
+00000008  38 39        |89|
+`,
+		},
+		{
+			name: "nothing used",
+			dump: func() *Dump {
+				d := New([]byte("abc"))
+				d.Used = 0
+				return d
+			},
+			want: `3 bytes: 0 used, 3 unused
+00000000  61 62 63                                          |abc|
+          [======]                                          unused
+`,
+		},
+		{
+			name: "all used",
+			dump: func() *Dump {
+				d := New([]byte("abc"))
+				d.Used = 10
+				return d
+			},
+			want: `3 bytes: 3 used, 0 unused
+00000000  61 62 63                                          |abc|
+`,
+		},
+		{
+			name: "empty",
+			dump: func() *Dump {
+				return New(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dump().String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     string
+		b     string
+		width int
+		want  string
+	}{
+		{
+			name: "different",
+			a:    "hello, world!",
+			b:    "hello, World",
+			want: `00000000  68 65 6c 6c 6f 2c 20 77  |hello, w|   68 65 6c 6c 6f 2c 20 57  |hello, W|
+                               ^^                                    ^^
+00000008  6f 72 6c 64 21           |orld!   |   6f 72 6c 64              |orld    |
+                      ^^                                    ^^
+a: 13 bytes, b: 12 bytes, 2 bytes differ, first at offset 7 (0x7)
+`,
+		},
+		{
+			name:  "identical",
+			a:     "same",
+			b:     "same",
+			width: 4,
+			want: `00000000  73 61 6d 65  |same|   73 61 6d 65  |same|
+identical: 4 bytes
+`,
+		},
+		{
+			name:  "empty",
+			a:     "",
+			b:     "ab",
+			width: 2,
+			want: `00000000         |  |   61 62  |ab|
+          ^^ ^^         ^^ ^^
+a: 0 bytes, b: 2 bytes, 2 bytes differ, first at offset 0 (0x0)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Diff(&b, []byte(tt.a), []byte(tt.b), tt.width); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+// failWriter fails every write.
+type failWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestWriteErrors(t *testing.T) {
+	d := New([]byte("abc"))
+	d.Used = 1
+	if err := d.Write(failWriter{}); !errors.Is(err, errWrite) {
+		t.Errorf("Write = %v, want %v", err, errWrite)
+	}
+	if err := Diff(failWriter{}, []byte("a"), []byte("b"), 0); !errors.Is(err, errWrite) {
+		t.Errorf("Diff = %v, want %v", err, errWrite)
+	}
+}
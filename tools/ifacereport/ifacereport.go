@@ -0,0 +1,335 @@
+// Package ifacereport produces a report of every named interface in a set of
+// packages, who implements it and how it is used.
+//
+// The course teaches to decouple around behavior, but it also warns about
+// interface pollution: an interface should be discovered, not designed up
+// front. The report flags the usual smells.
+//
+//   - The interface has a single implementation, so the abstraction is not
+//     buying any decoupling yet.
+//   - The interface is never used as a parameter type, so no function
+//     actually accepts the behavior.
+//   - A constructor returns the interface instead of the concrete type.
+//     Accept interfaces, return concrete types.
+//
+// Constructors are recognized by name only: a function without a receiver
+// whose name starts with New or new. A factory with any other name, like
+// Open or FromConfig, is not checked.
+package ifacereport
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ultimate-go-programming/tools/analysis"
+)
+
+// Implementer is a named type that satisfies an interface.
+type Implementer struct {
+	Type        string `json:"type"`
+	Pos         string `json:"pos"`
+	PointerOnly bool   `json:"pointer_only"`
+}
+
+// String implements the fmt.Stringer interface.
+func (i Implementer) String() string {
+	if i.PointerOnly {
+		return fmt.Sprintf("*%s (pointer method set only)", i.Type)
+	}
+	return fmt.Sprintf("%s (value and pointer method sets)", i.Type)
+}
+
+// Interface holds everything the report knows about one interface.
+type Interface struct {
+	Name         string        `json:"name"`
+	Pos          string        `json:"pos"`
+	Methods      []string      `json:"methods"`
+	Implementers []Implementer `json:"implementers"`
+	Params       []string      `json:"params"`
+	CallSites    []string      `json:"call_sites"`
+	Constructors []string      `json:"constructors"`
+	Findings     []string      `json:"findings"`
+}
+
+// Report is the result of analyzing a set of packages.
+type Report struct {
+	Interfaces []*Interface `json:"interfaces"`
+}
+
+// Build analyzes the packages and produces the report. Implementers are
+// searched for across every package, so packages should be loaded by the
+// same analysis.Loader to share type identity.
+func Build(pkgs []*analysis.Package) *Report {
+	var ifaces []*types.Named
+	var concrete []*types.Named
+	entries := make(map[*types.Named]*Interface)
+
+	for _, pkg := range pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+
+			if iface, ok := named.Underlying().(*types.Interface); ok {
+				if iface.Empty() {
+					continue
+				}
+				ifaces = append(ifaces, named)
+				entries[named] = &Interface{
+					Name:    qualifiedName(named),
+					Pos:     position(pkg.Fset, tn.Pos()),
+					Methods: methods(iface),
+				}
+				continue
+			}
+
+			concrete = append(concrete, named)
+		}
+	}
+
+	for _, named := range ifaces {
+		entry := entries[named]
+		iface := named.Underlying().(*types.Interface)
+
+		for _, t := range concrete {
+			switch {
+			case types.Implements(t, iface):
+				entry.Implementers = append(entry.Implementers, implementer(pkgs, t, false))
+			case types.Implements(types.NewPointer(t), iface):
+				entry.Implementers = append(entry.Implementers, implementer(pkgs, t, true))
+			}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		collectUses(pkg, entries)
+	}
+
+	r := Report{
+		Interfaces: make([]*Interface, 0, len(ifaces)),
+	}
+
+	for _, named := range ifaces {
+		entry := entries[named]
+		entry.Findings = findings(entry)
+		r.Interfaces = append(r.Interfaces, entry)
+	}
+
+	sort.Slice(r.Interfaces, func(i, j int) bool {
+		return r.Interfaces[i].Name < r.Interfaces[j].Name
+	})
+
+	return &r
+}
+
+// WriteText writes the report in a human readable form.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	for i, iface := range r.Interfaces {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(&b, "%s\t%s\n", iface.Name, iface.Pos)
+		fmt.Fprintf(&b, "  methods: %s\n", strings.Join(iface.Methods, ", "))
+
+		section(&b, "implementers", iface.Implementers)
+		section(&b, "parameters", iface.Params)
+		section(&b, "call sites", iface.CallSites)
+		section(&b, "constructors", iface.Constructors)
+
+		for _, f := range iface.Findings {
+			fmt.Fprintf(&b, "  FLAG: %s\n", f)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// section writes a titled list of values.
+func section[T any](b *strings.Builder, title string, values []T) {
+	if len(values) == 0 {
+		fmt.Fprintf(b, "  %s: none\n", title)
+		return
+	}
+
+	fmt.Fprintf(b, "  %s:\n", title)
+	for _, v := range values {
+		fmt.Fprintf(b, "    %v\n", v)
+	}
+}
+
+// findings applies the course guidance to what is known about an interface.
+func findings(iface *Interface) []string {
+	var f []string
+
+	switch len(iface.Implementers) {
+	case 0:
+		f = append(f, "no implementations: the interface describes behavior nothing exhibits")
+	case 1:
+		f = append(f, fmt.Sprintf("single implementation %s: use the concrete type until a second implementation appears", iface.Implementers[0].Type))
+	}
+
+	if len(iface.Params) == 0 {
+		f = append(f, "never used as a parameter type: no function accepts this behavior, so nothing is decoupled")
+	}
+
+	for _, c := range iface.Constructors {
+		f = append(f, fmt.Sprintf("returned from constructor %s: accept interfaces, return concrete types", c))
+	}
+
+	return f
+}
+
+// collectUses records parameters, method calls and constructors that involve
+// the interfaces declared in the report.
+func collectUses(pkg *analysis.Package, entries map[*types.Named]*Interface) {
+	info := pkg.Info
+
+	lookup := func(t types.Type) *Interface {
+		if s, ok := t.(*types.Slice); ok {
+			t = s.Elem()
+		}
+		if named, ok := t.(*types.Named); ok {
+			return entries[named]
+		}
+		return nil
+	}
+
+	params := func(name string, fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			if entry := lookup(info.TypeOf(field.Type)); entry != nil {
+				entry.Params = append(entry.Params, fmt.Sprintf("%s\t%s", name, position(pkg.Fset, field.Pos())))
+			}
+		}
+	}
+
+	for _, file := range pkg.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				name := funcName(pkg.Types, n)
+				params(name, n.Type.Params)
+
+				if n.Recv == nil && isConstructor(n.Name.Name) && n.Type.Results != nil {
+					for _, field := range n.Type.Results.List {
+						if entry := lookup(info.TypeOf(field.Type)); entry != nil {
+							entry.Constructors = append(entry.Constructors, fmt.Sprintf("%s\t%s", name, position(pkg.Fset, n.Pos())))
+						}
+					}
+				}
+
+			case *ast.FuncLit:
+				params("func literal", n.Type.Params)
+
+			case *ast.SelectorExpr:
+				sel, ok := info.Selections[n]
+				if !ok || sel.Kind() != types.MethodVal {
+					return true
+				}
+				if entry := lookup(sel.Recv()); entry != nil && types.IsInterface(sel.Recv()) {
+					entry.CallSites = append(entry.CallSites, fmt.Sprintf("%s\t%s", n.Sel.Name, position(pkg.Fset, n.Sel.Pos())))
+				}
+			}
+			return true
+		})
+	}
+}
+
+// implementer constructs the implementer entry for a concrete type.
+func implementer(pkgs []*analysis.Package, t *types.Named, pointerOnly bool) Implementer {
+	obj := t.Obj()
+
+	var pos string
+	for _, pkg := range pkgs {
+		if pkg.Types == obj.Pkg() {
+			pos = position(pkg.Fset, obj.Pos())
+			break
+		}
+	}
+
+	return Implementer{
+		Type:        qualifiedName(t),
+		Pos:         pos,
+		PointerOnly: pointerOnly,
+	}
+}
+
+// methods returns the signatures of every method in the interface,
+// including those from embedded interfaces.
+func methods(iface *types.Interface) []string {
+	list := make([]string, iface.NumMethods())
+	for i := range iface.NumMethods() {
+		m := iface.Method(i)
+		sig := types.TypeString(m.Type(), func(p *types.Package) string { return p.Name() })
+		list[i] = m.Name() + strings.TrimPrefix(sig, "func")
+	}
+	return list
+}
+
+// funcName returns the name of a function declaration including the
+// receiver type for methods.
+func funcName(pkg *types.Package, fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return pkg.Name() + "." + fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if id, ok := recv.(*ast.Ident); ok {
+		return fmt.Sprintf("%s.%s.%s", pkg.Name(), id.Name, fn.Name.Name)
+	}
+
+	return pkg.Name() + "." + fn.Name.Name
+}
+
+// isConstructor reports whether the function name follows the factory
+// function naming convention.
+func isConstructor(name string) bool {
+	return strings.HasPrefix(name, "New") || strings.HasPrefix(name, "new")
+}
+
+// qualifiedName returns the type name qualified by its package name.
+func qualifiedName(t *types.Named) string {
+	obj := t.Obj()
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Name() + "." + obj.Name()
+}
+
+// position formats a position as file:line with the file relative to the
+// working directory so reports stay stable across machines.
+func position(fset *token.FileSet, pos token.Pos) string {
+	p := fset.Position(pos)
+
+	name := p.Filename
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+	}
+
+	return fmt.Sprintf("%s:%d", name, p.Line)
+}
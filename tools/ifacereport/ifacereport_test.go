@@ -0,0 +1,63 @@
+package ifacereport
+
+import (
+	"strings"
+	"testing"
+
+	"ultimate-go-programming/tools/analysis"
+)
+
+func TestBuild(t *testing.T) {
+	l, err := analysis.NewLoader("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := l.LoadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := Build([]*analysis.Package{pkg}).WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `shapes.notifier	testdata/shapes.go:60
+  methods: notify(msg string)
+  implementers: none
+  parameters:
+    shapes.broadcast	testdata/shapes.go:64
+  call sites:
+    notify	testdata/shapes.go:66
+  constructors: none
+  FLAG: no implementations: the interface describes behavior nothing exhibits
+
+shapes.shape	testdata/shapes.go:6
+  methods: area() float64
+  implementers:
+    *shapes.circle (pointer method set only)
+    shapes.square (value and pointer method sets)
+  parameters:
+    shapes.total	testdata/shapes.go:26
+  call sites:
+    area	testdata/shapes.go:29
+  constructors:
+    shapes.NewSquare	testdata/shapes.go:35
+  FLAG: returned from constructor shapes.NewSquare	testdata/shapes.go:35: accept interfaces, return concrete types
+
+shapes.store	testdata/shapes.go:45
+  methods: save(key string) error
+  implementers:
+    shapes.memory (value and pointer method sets)
+  parameters: none
+  call sites: none
+  constructors:
+    shapes.newStore	testdata/shapes.go:55
+  FLAG: single implementation shapes.memory: use the concrete type until a second implementation appears
+  FLAG: never used as a parameter type: no function accepts this behavior, so nothing is decoupled
+  FLAG: returned from constructor shapes.newStore	testdata/shapes.go:55: accept interfaces, return concrete types
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,71 @@
+package shapes
+
+import "math"
+
+// shape has two implementations and is accepted by a function.
+type shape interface {
+	area() float64
+}
+
+type square struct {
+	side float64
+}
+
+func (s square) area() float64 {
+	return s.side * s.side
+}
+
+type circle struct {
+	radius float64
+}
+
+func (c *circle) area() float64 {
+	return math.Pi * c.radius * c.radius
+}
+
+func total(shapes ...shape) float64 {
+	var sum float64
+	for _, s := range shapes {
+		sum += s.area()
+	}
+	return sum
+}
+
+// NewSquare returns the interface instead of the concrete type.
+func NewSquare(side float64) shape {
+	return square{side}
+}
+
+// Open is a factory too, but it is not named like a constructor.
+func Open(radius float64) shape {
+	return &circle{radius}
+}
+
+// store has a single implementation and nothing accepts it.
+type store interface {
+	save(key string) error
+}
+
+type memory struct{}
+
+func (memory) save(key string) error {
+	return nil
+}
+
+func newStore() store {
+	return memory{}
+}
+
+// notifier has no implementation at all.
+type notifier interface {
+	notify(msg string)
+}
+
+func broadcast(ns []notifier) {
+	for _, n := range ns {
+		n.notify("hi")
+	}
+}
+
+// The empty interface is not reported.
+type anything interface{}
@@ -0,0 +1,202 @@
+// Package methodset builds a matrix of which named types in a package
+// satisfy which interfaces, for both values and pointers.
+//
+// The method set rules from the lessons decide every cell.
+//
+//   - The method set of a value of type T holds the methods declared with
+//     a value receiver.
+//   - The method set of a pointer of type *T holds the methods declared
+//     with both value and pointer receivers.
+//   - Methods of embedded types are promoted into the outer type, following
+//     the same rules for the embedded field.
+//
+// When a type doesn't satisfy an interface the cell explains why: a method
+// is missing, only exists with a pointer receiver, or has a different
+// signature.
+package methodset
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Set of reasons a type can fail to satisfy an interface.
+const (
+	Missing         = "missing"
+	PointerReceiver = "pointer-receiver"
+	WrongSignature  = "wrong-signature"
+)
+
+// Cell describes whether one type satisfies one interface.
+type Cell struct {
+	Interface string `json:"interface"`
+	Satisfies bool   `json:"satisfies"`
+	Kind      string `json:"kind,omitempty"`
+	Method    string `json:"method,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// short returns the text used for the cell in the terminal table.
+func (c Cell) short() string {
+	switch c.Kind {
+	case "":
+		return "yes"
+	case PointerReceiver:
+		return "no: " + c.Method + " has ptr recv"
+	case WrongSignature:
+		return "no: " + c.Method + " signature"
+	}
+	return "no: missing " + c.Method
+}
+
+// Row holds the cells for a single type, T or *T.
+type Row struct {
+	Type  string `json:"type"`
+	Cells []Cell `json:"cells"`
+}
+
+// Matrix is the result of comparing every type against every interface.
+type Matrix struct {
+	Package    string   `json:"package"`
+	Interfaces []string `json:"interfaces"`
+	Rows       []Row    `json:"rows"`
+}
+
+// Build compares every named type declared in the package against every
+// interface declared in the package plus the extra interfaces provided.
+func Build(pkg *types.Package, extra ...*types.Named) *Matrix {
+	var ifaces []*types.Named
+	var concrete []*types.Named
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+
+		named, ok := tn.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+
+		if types.IsInterface(named) {
+			ifaces = append(ifaces, named)
+			continue
+		}
+		concrete = append(concrete, named)
+	}
+	ifaces = append(ifaces, extra...)
+
+	qual := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+
+	m := Matrix{
+		Package: pkg.Path(),
+	}
+
+	for _, iface := range ifaces {
+		m.Interfaces = append(m.Interfaces, types.TypeString(iface, qual))
+	}
+
+	for _, named := range concrete {
+		for _, t := range []types.Type{named, types.NewPointer(named)} {
+			row := Row{
+				Type: types.TypeString(t, qual),
+			}
+
+			for i, iface := range ifaces {
+				cell := check(t, iface, qual)
+				cell.Interface = m.Interfaces[i]
+				row.Cells = append(row.Cells, cell)
+			}
+
+			m.Rows = append(m.Rows, row)
+		}
+	}
+
+	sort.SliceStable(m.Rows, func(i, j int) bool {
+		return strings.TrimPrefix(m.Rows[i].Type, "*") < strings.TrimPrefix(m.Rows[j].Type, "*")
+	})
+
+	return &m
+}
+
+// check determines whether the type satisfies the interface and why not.
+func check(t types.Type, named *types.Named, qual types.Qualifier) Cell {
+	iface := named.Underlying().(*types.Interface)
+
+	missing, wrongType := types.MissingMethod(t, iface, true)
+	if missing == nil {
+		return Cell{Satisfies: true}
+	}
+
+	want := types.TypeString(missing.Type(), qual)
+	obj, _, indirect := types.LookupFieldOrMethod(t, false, missing.Pkg(), missing.Name())
+
+	switch {
+	case obj == nil && indirect:
+		return Cell{
+			Kind:   PointerReceiver,
+			Method: missing.Name(),
+			Reason: fmt.Sprintf("method %s has a pointer receiver and is not in the method set of %s", missing.Name(), types.TypeString(t, qual)),
+		}
+
+	case wrongType && obj != nil:
+		return Cell{
+			Kind:   WrongSignature,
+			Method: missing.Name(),
+			Reason: fmt.Sprintf("method %s has signature %s, want %s", missing.Name(), types.TypeString(obj.Type(), qual), want),
+		}
+	}
+
+	return Cell{
+		Kind:   Missing,
+		Method: missing.Name(),
+		Reason: fmt.Sprintf("missing method %s%s", missing.Name(), strings.TrimPrefix(want, "func")),
+	}
+}
+
+// WriteTable writes the matrix as an aligned terminal table followed by
+// the full reason for every cell that is not satisfied.
+func (m *Matrix) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "TYPE\t%s\n", strings.Join(m.Interfaces, "\t"))
+	for _, row := range m.Rows {
+		cells := make([]string, len(row.Cells))
+		for i, c := range row.Cells {
+			cells[i] = c.short()
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", row.Type, strings.Join(cells, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var reasons []string
+	for _, row := range m.Rows {
+		for _, c := range row.Cells {
+			if c.Kind == PointerReceiver || c.Kind == WrongSignature {
+				reasons = append(reasons, fmt.Sprintf("  %s does not satisfy %s: %s", row.Type, c.Interface, c.Reason))
+			}
+		}
+	}
+
+	if len(reasons) > 0 {
+		if _, err := fmt.Fprintf(w, "\nNear misses:\n%s\n", strings.Join(reasons, "\n")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package methodset
+
+import (
+	"strings"
+	"testing"
+
+	"ultimate-go-programming/tools/analysis"
+)
+
+func TestBuild(t *testing.T) {
+	l, err := analysis.NewLoader("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := l.LoadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := Build(pkg.Types).WriteTable(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `TYPE         notifier                 printer
+loud         no: notify signature     no: missing print
+*loud        no: notify signature     no: missing print
+owner        yes                      yes
+*owner       yes                      yes
+person       no: notify has ptr recv  no: missing print
+*person      yes                      no: missing print
+silent       no: missing notify       no: missing print
+*silent      no: missing notify       no: missing print
+superadmin   no: notify has ptr recv  yes
+*superadmin  yes                      yes
+user         no: notify has ptr recv  yes
+*user        yes                      yes
+useradmin    no: notify has ptr recv  yes
+*useradmin   yes                      yes
+
+Near misses:
+  loud does not satisfy notifier: method notify has signature func(level int), want func()
+  *loud does not satisfy notifier: method notify has signature func(level int), want func()
+  person does not satisfy notifier: method notify has a pointer receiver and is not in the method set of person
+  superadmin does not satisfy notifier: method notify has a pointer receiver and is not in the method set of superadmin
+  user does not satisfy notifier: method notify has a pointer receiver and is not in the method set of user
+  useradmin does not satisfy notifier: method notify has a pointer receiver and is not in the method set of useradmin
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
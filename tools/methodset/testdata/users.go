@@ -0,0 +1,51 @@
+package users
+
+type notifier interface {
+	notify()
+}
+
+type printer interface {
+	print(msg string)
+}
+
+// person only has the pointer receiver method.
+type person struct {
+	name string
+}
+
+func (p *person) notify() {}
+
+// user has a method of each kind.
+type user struct {
+	name string
+}
+
+func (u *user) notify() {}
+
+func (u user) print(msg string) {}
+
+// useradmin gets the methods of user promoted.
+type useradmin struct {
+	user
+	level string
+}
+
+// superadmin declares its own pointer receiver notify.
+type superadmin struct {
+	user
+	level string
+}
+
+func (a *superadmin) notify() {}
+
+// owner embeds a pointer, so both method sets hold notify.
+type owner struct {
+	*user
+}
+
+// loud has notify with the wrong signature.
+type loud struct{}
+
+func (loud) notify(level int) {}
+
+type silent struct{}
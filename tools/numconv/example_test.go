@@ -0,0 +1,74 @@
+package numconv_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"ultimate-go-programming/tools/numconv"
+	"ultimate-go-programming/tools/table"
+)
+
+// The compiler rejects int8(300) for a constant, but converting a variable
+// that holds 300 compiles and wraps around to 44. Convert reports what the
+// conversion would lose instead.
+func ExampleConvert() {
+	values := []float64{10, 300, -1, 3.7, math.Pi, 1<<24 + 1, math.NaN()}
+
+	t := table.New("value", "int8", "uint8", "int32", "float32")
+	for _, v := range values {
+		t.AddRow(v, convertCell[int8](v), convertCell[uint8](v), convertCell[int32](v), convertCell[float32](v))
+	}
+	fmt.Println(t)
+
+	// Output:
+	// value              int8      uint8     int32     float32
+	// 10                 10        10        10        10
+	// 300                overflow  overflow  300       300
+	// -1                 -1        overflow  -1        -1
+	// 3.7                fraction  fraction  fraction  precision
+	// 3.141592653589793  fraction  fraction  fraction  precision
+	// 1.6777217e+07      overflow  overflow  16777217  precision
+	// NaN                NaN       NaN       NaN       NaN
+}
+
+// Saturate clamps to the range of the type.
+func ExampleSaturate() {
+	big := 300
+	fmt.Println("Saturate[int8](300) =", numconv.Saturate[int8](big))
+	fmt.Println("Saturate[uint8](-1) =", numconv.Saturate[uint8](-1))
+
+	// Output:
+	// Saturate[int8](300) = 127
+	// Saturate[uint8](-1) = 0
+}
+
+// Wrap keeps the low bits the way the plain conversion does.
+func ExampleWrap() {
+	big := 300
+	fmt.Println("int8(300)       =", int8(big))
+	fmt.Println("Wrap[int8](300) =", numconv.Wrap[int8](big))
+	fmt.Println("Wrap[uint8](-1) =", numconv.Wrap[uint8](-1))
+
+	// Output:
+	// int8(300)       = 44
+	// Wrap[int8](300) = 44
+	// Wrap[uint8](-1) = 255
+}
+
+// convertCell converts the value to the type T and returns the result, or
+// what the conversion would lose.
+func convertCell[T numconv.Number](v float64) string {
+	n, err := numconv.Convert[T](v)
+	switch {
+	case errors.Is(err, numconv.ErrOverflow):
+		return "overflow"
+	case errors.Is(err, numconv.ErrFraction):
+		return "fraction"
+	case errors.Is(err, numconv.ErrPrecision):
+		return "precision"
+	case errors.Is(err, numconv.ErrNaN):
+		return "NaN"
+	}
+	return fmt.Sprint(n)
+}
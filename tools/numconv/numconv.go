@@ -0,0 +1,269 @@
+// Package numconv converts between the numeric types and reports what a
+// plain conversion silently loses.
+//
+// The compiler rejects int8(300) because 300 is a constant that doesn't fit,
+// but the same conversion of a variable compiles and yields 44. Converting a
+// float to an integer drops the fraction, and converting an integer or a
+// float64 to a smaller float rounds to the nearest value the type can hold.
+// Convert returns an error in each of those cases instead. Saturate and Wrap
+// never fail: Saturate clamps to the closest value the type can hold and
+// Wrap keeps the low bits the way a conversion of an integer does.
+package numconv
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"unsafe"
+)
+
+// Integer is the set of integer types.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float is the set of floating point types.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is the set of types Convert works with.
+type Number interface {
+	Integer | Float
+}
+
+// Set of errors returned by Convert.
+var (
+	ErrOverflow  = errors.New("value out of range")
+	ErrFraction  = errors.New("fractional part lost")
+	ErrPrecision = errors.New("precision lost")
+	ErrNaN       = errors.New("NaN has no integer value")
+)
+
+// Convert converts the value to the type To. It returns the zero value and
+// an error when the value is out of the range of To, when a float has a
+// fraction To can't hold or when To can't hold the value exactly. NaN and
+// the infinities convert to the float types without error.
+func Convert[To Number, From Number](v From) (To, error) {
+	to := kind[To]()
+	from := split(v)
+
+	fail := func(err error) (To, error) {
+		var zero To
+		return zero, fmt.Errorf("convert %v from %T to %T: %w", v, v, zero, err)
+	}
+
+	switch {
+	case to.float && from.float:
+		f := from.f
+		if to.bits == 64 || math.IsNaN(f) || math.IsInf(f, 0) {
+			return To(f), nil
+		}
+		if math.Abs(f) > math.MaxFloat32 {
+			return fail(ErrOverflow)
+		}
+		if float64(float32(f)) != f {
+			return fail(ErrPrecision)
+		}
+		return To(f), nil
+
+	case to.float:
+		mantissa := 53
+		if to.bits == 32 {
+			mantissa = 24
+		}
+		if from.mag != 0 && bits.Len64(from.mag)-bits.TrailingZeros64(from.mag) > mantissa {
+			return fail(ErrPrecision)
+		}
+		return build[To](from), nil
+
+	case from.float:
+		f := from.f
+		if math.IsNaN(f) {
+			return fail(ErrNaN)
+		}
+		t := math.Trunc(f)
+		if !to.holds(t) {
+			return fail(ErrOverflow)
+		}
+		if t != f {
+			return fail(ErrFraction)
+		}
+		return To(t), nil
+	}
+
+	if from.neg && !to.signed || from.mag > to.max(from.neg) {
+		return fail(ErrOverflow)
+	}
+	return build[To](from), nil
+}
+
+// Saturate converts the value to the type To, clamping it to the smallest
+// or largest value To can hold. Floats converted to an integer type lose
+// their fraction and NaN becomes zero. Floats converted to a smaller float
+// are rounded to the nearest value, and finite values too large for it
+// become its largest finite value.
+func Saturate[To Number, From Number](v From) To {
+	to := kind[To]()
+	from := split(v)
+
+	switch {
+	case to.float && from.float:
+		f := from.f
+		if to.bits == 32 && !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+			return To(math.Copysign(math.MaxFloat32, f))
+		}
+		return To(f)
+
+	case to.float:
+		return build[To](from)
+
+	case from.float:
+		f := from.f
+		switch {
+		case math.IsNaN(f):
+			return 0
+		case f < 0 && !to.holds(math.Trunc(f)):
+			return minOf[To](to)
+		case !to.holds(math.Trunc(f)):
+			return maxOf[To](to)
+		}
+		return To(math.Trunc(f))
+	}
+
+	switch {
+	case from.neg && !to.signed:
+		return 0
+	case from.mag > to.max(from.neg) && from.neg:
+		return minOf[To](to)
+	case from.mag > to.max(from.neg):
+		return maxOf[To](to)
+	}
+	return build[To](from)
+}
+
+// Wrap converts the value to the type To keeping its low bits, the way
+// int8(x) wraps an integer x around. Floats converted to an integer type
+// lose their fraction first and NaN and the infinities become zero.
+// Converting to a float type is the same as a plain conversion since floats
+// don't wrap.
+func Wrap[To Number, From Number](v From) To {
+	to := kind[To]()
+	from := split(v)
+
+	switch {
+	case to.float && from.float:
+		return To(from.f)
+
+	case to.float:
+		return build[To](from)
+
+	case from.float:
+		f := from.f
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0
+		}
+
+		// The remainder is exact and its magnitude is below 1<<64.
+		m := math.Mod(math.Trunc(f), 1<<64)
+		u := uint64(math.Abs(m))
+		if m < 0 {
+			u = -u
+		}
+		return To(u)
+	}
+
+	// Integer conversions already wrap.
+	return build[To](from)
+}
+
+// =============================================================================
+
+// numKind describes a numeric type.
+type numKind struct {
+	float  bool
+	signed bool
+	bits   uint
+}
+
+// kind returns the description of the type T.
+func kind[T Number]() numKind {
+	var zero T
+	one := T(1)
+	return numKind{
+		float:  one/2 != 0,
+		signed: zero-one < 0,
+		bits:   uint(unsafe.Sizeof(zero)) * 8,
+	}
+}
+
+// max returns the magnitude of the largest value of the integer type, or of
+// its smallest value when neg is set.
+func (k numKind) max(neg bool) uint64 {
+	switch {
+	case !k.signed && neg:
+		return 0
+	case !k.signed:
+		return math.MaxUint64 >> (64 - k.bits)
+	case neg:
+		return 1 << (k.bits - 1)
+	}
+	return 1<<(k.bits-1) - 1
+}
+
+// holds reports whether the integer type holds the integral float.
+func (k numKind) holds(t float64) bool {
+	if !k.signed {
+		return t >= 0 && t < math.Ldexp(1, int(k.bits))
+	}
+	limit := math.Ldexp(1, int(k.bits)-1)
+	return t >= -limit && t < limit
+}
+
+// minOf returns the smallest value of the integer type T.
+func minOf[T Number](k numKind) T {
+	if !k.signed {
+		return 0
+	}
+	return T(-int64(k.max(true)))
+}
+
+// maxOf returns the largest value of the integer type T.
+func maxOf[T Number](k numKind) T {
+	return T(k.max(false))
+}
+
+// num is a value of any numeric type: a float or the sign and magnitude of
+// an integer.
+type num struct {
+	float bool
+	f     float64
+	neg   bool
+	mag   uint64
+}
+
+// split returns the value as a num. Every float32 and float64 is held
+// exactly by a float64.
+func split[T Number](v T) num {
+	k := kind[T]()
+	switch {
+	case k.float:
+		return num{float: true, f: float64(v)}
+	case k.signed && v < 0:
+		return num{neg: true, mag: -uint64(int64(v))}
+	case k.signed:
+		return num{mag: uint64(int64(v))}
+	}
+	return num{mag: uint64(v)}
+}
+
+// build converts the integer to the type T. The conversion wraps when T
+// can't hold it and rounds to the nearest value when T is a float type.
+func build[T Number](n num) T {
+	if n.neg {
+		return T(-int64(n.mag))
+	}
+	return T(n.mag)
+}
@@ -0,0 +1,402 @@
+package numconv
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertExamples(t *testing.T) {
+	type celsius int16
+
+	tests := []struct {
+		name string
+		got  func() (any, error)
+		want any
+		err  error
+	}{
+		{"fits", func() (any, error) { return Convert[int8](100) }, int8(100), nil},
+		{"too large", func() (any, error) { return Convert[int8](300) }, int8(0), ErrOverflow},
+		{"negative to unsigned", func() (any, error) { return Convert[uint](-1) }, uint(0), ErrOverflow},
+		{"largest uint64 to int64", func() (any, error) { return Convert[int64](uint64(math.MaxUint64)) }, int64(0), ErrOverflow},
+		{"smallest int64 to uint64", func() (any, error) { return Convert[uint64](int64(math.MinInt64)) }, uint64(0), ErrOverflow},
+		{"fraction", func() (any, error) { return Convert[int](2.5) }, 0, ErrFraction},
+		{"whole float", func() (any, error) { return Convert[int](-2.0) }, -2, nil},
+		{"NaN", func() (any, error) { return Convert[int32](math.NaN()) }, int32(0), ErrNaN},
+		{"infinity", func() (any, error) { return Convert[int64](math.Inf(1)) }, int64(0), ErrOverflow},
+		{"2^63 to int64", func() (any, error) { return Convert[int64](float64(1 << 63)) }, int64(0), ErrOverflow},
+		{"-2^63 to int64", func() (any, error) { return Convert[int64](-float64(1 << 63)) }, int64(math.MinInt64), nil},
+		{"odd int to float32", func() (any, error) { return Convert[float32](1<<24 + 1) }, float32(0), ErrPrecision},
+		{"even int to float32", func() (any, error) { return Convert[float32](1<<24 + 2) }, float32(1<<24 + 2), nil},
+		{"odd int to float64", func() (any, error) { return Convert[float64](int64(1<<53 + 1)) }, float64(0), ErrPrecision},
+		{"0.1 to float32", func() (any, error) { return Convert[float32](0.1) }, float32(0), ErrPrecision},
+		{"large float64 to float32", func() (any, error) { return Convert[float32](1e39) }, float32(0), ErrOverflow},
+		{"infinity to float32", func() (any, error) { return Convert[float32](math.Inf(-1)) }, float32(math.Inf(-1)), nil},
+		{"named type", func() (any, error) { return Convert[celsius](40000) }, celsius(0), ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+
+	_, err := Convert[celsius](40000)
+	if want := "convert 40000 from int to numconv.celsius: value out of range"; err == nil || err.Error() != want {
+		t.Errorf("error text = %v, want %q", err, want)
+	}
+}
+
+func TestSaturateWrapExamples(t *testing.T) {
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"saturate large", Saturate[int8](300), int8(127)},
+		{"saturate small", Saturate[int8](-300), int8(-128)},
+		{"saturate negative to unsigned", Saturate[uint8](-5), uint8(0)},
+		{"saturate fraction", Saturate[uint8](3.9), uint8(3)},
+		{"saturate NaN", Saturate[int32](math.NaN()), int32(0)},
+		{"saturate infinity", Saturate[int64](math.Inf(-1)), int64(math.MinInt64)},
+		{"saturate uint64 to int64", Saturate[int64](uint64(1 << 63)), int64(math.MaxInt64)},
+		{"saturate float32", Saturate[float32](1e300), float32(math.MaxFloat32)},
+		{"saturate float32 infinity", Saturate[float32](math.Inf(1)), float32(math.Inf(1))},
+		{"wrap", Wrap[int8](300), int8(44)},
+		{"wrap negative to unsigned", Wrap[uint8](-1.5), uint8(255)},
+		{"wrap fraction", Wrap[int8](384.7), int8(-128)},
+		{"wrap large float", Wrap[uint32](float64(1<<40 + 5)), uint32(5)},
+		{"wrap infinity", Wrap[int64](math.Inf(1)), int64(0)},
+		{"wrap float32", Wrap[float32](1e300), float32(math.Inf(1))},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v (%T), want %v (%T)", tt.name, tt.got, tt.got, tt.want, tt.want)
+		}
+	}
+}
+
+// TestConvertAll converts a set of values of every numeric type to every
+// numeric type and checks Convert, Saturate and Wrap against a reference
+// computed with math/big. The values are every value of the 8 and 16 bit
+// types, the limits of every type and their neighbours, NaN, the
+// infinities and random floats.
+func TestConvertAll(t *testing.T) {
+	checkFrom(t, values[int8]())
+	checkFrom(t, values[int16]())
+	checkFrom(t, values[int32]())
+	checkFrom(t, values[int64]())
+	checkFrom(t, values[int]())
+	checkFrom(t, values[uint8]())
+	checkFrom(t, values[uint16]())
+	checkFrom(t, values[uint32]())
+	checkFrom(t, values[uint64]())
+	checkFrom(t, values[uint]())
+	checkFrom(t, values[uintptr]())
+	checkFrom(t, values[float32]())
+	checkFrom(t, values[float64]())
+}
+
+// checkFrom checks the conversion of the values to every type.
+func checkFrom[From Number](t *testing.T, vs []From) {
+	checkTo[int8](t, vs)
+	checkTo[int16](t, vs)
+	checkTo[int32](t, vs)
+	checkTo[int64](t, vs)
+	checkTo[int](t, vs)
+	checkTo[uint8](t, vs)
+	checkTo[uint16](t, vs)
+	checkTo[uint32](t, vs)
+	checkTo[uint64](t, vs)
+	checkTo[uint](t, vs)
+	checkTo[uintptr](t, vs)
+	checkTo[float32](t, vs)
+	checkTo[float64](t, vs)
+}
+
+// checkTo checks the conversion of the values to To.
+func checkTo[To Number, From Number](t *testing.T, vs []From) {
+	var failed int
+	fail := func(format string, args ...any) {
+		if failed++; failed <= 10 {
+			t.Errorf(format, args...)
+		}
+	}
+
+	for _, v := range vs {
+		x := exact(v)
+
+		got, err := Convert[To](v)
+		want, wantErr := refConvert[To](x)
+		if !errors.Is(err, wantErr) || (err == nil) != (wantErr == nil) || !same(got, want) {
+			fail("Convert[%T](%T(%v)) = %v, %v, want %v, %v", got, v, v, got, err, want, wantErr)
+		}
+
+		if got, want := Saturate[To](v), refSaturate[To](x); !same(got, want) {
+			fail("Saturate[%T](%T(%v)) = %v, want %v", got, v, v, got, want)
+		}
+
+		if got, want := Wrap[To](v), refWrap[To](x); !same(got, want) {
+			fail("Wrap[%T](%T(%v)) = %v, want %v", got, v, v, got, want)
+		}
+	}
+}
+
+// =============================================================================
+
+// values returns the values of T to convert.
+func values[T Number]() []T {
+	typ := reflect.TypeFor[T]()
+
+	var list []T
+	if isFloat(typ) {
+		for _, f := range floats() {
+			if typ.Bits() == 64 || math.IsNaN(f) || float64(float32(f)) == f {
+				list = append(list, T(f))
+			}
+		}
+		return list
+	}
+
+	lo, hi := limits(typ)
+	if typ.Bits() <= 16 {
+		for i := lo.Int64(); i <= hi.Int64(); i++ {
+			list = append(list, T(i))
+		}
+		return list
+	}
+
+	for _, b := range integers() {
+		if b.Cmp(lo) >= 0 && b.Cmp(hi) <= 0 {
+			list = append(list, fromBig[T](b))
+		}
+	}
+	return list
+}
+
+// integers returns the limits of every integer type and their neighbours,
+// and the integers around the limits of exact floats.
+func integers() []*big.Int {
+	var list []*big.Int
+	add := func(b *big.Int) {
+		for d := int64(-1); d <= 1; d++ {
+			list = append(list, new(big.Int).Add(b, big.NewInt(d)))
+		}
+	}
+
+	add(big.NewInt(0))
+	for _, bits := range []uint{8, 16, 24, 32, 53, 63, 64} {
+		p := new(big.Int).Lsh(big.NewInt(1), bits)
+		add(p)
+		add(new(big.Int).Neg(p))
+	}
+	return list
+}
+
+// floats returns the limits of every type as floats and their neighbours,
+// special values and random floats.
+func floats() []float64 {
+	list := []float64{
+		0, math.Copysign(0, -1), 0.1, 0.5, 1.5, 2.5, math.Pi,
+		math.NaN(), math.Inf(1), math.Inf(-1),
+		math.MaxFloat64, math.SmallestNonzeroFloat64,
+		math.MaxFloat32, math.SmallestNonzeroFloat32,
+		float64(math.Nextafter32(math.MaxFloat32, 0)),
+		math.Nextafter(math.MaxFloat32, math.Inf(1)),
+		math.MaxFloat32 * (1 + 1.0/(1<<24)), // Halfway to the next float32.
+	}
+
+	for _, b := range integers() {
+		f, _ := new(big.Float).SetInt(b).Float64()
+		list = append(list, f, math.Nextafter(f, math.Inf(1)), math.Nextafter(f, math.Inf(-1)))
+		list = append(list, float64(math.Nextafter32(float32(f), float32(math.Inf(1)))))
+		list = append(list, float64(math.Nextafter32(float32(f), float32(math.Inf(-1)))))
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for range 2000 {
+		list = append(list, math.Float64frombits(r.Uint64()), float64(math.Float32frombits(r.Uint32())))
+		list = append(list, r.NormFloat64()*float64(int64(1)<<r.Intn(64)))
+	}
+
+	n := len(list)
+	for _, f := range list[:n] {
+		list = append(list, -f)
+	}
+	return list
+}
+
+// value is the exact value of a number. X is nil for NaN and is infinite
+// for the infinities.
+type value struct {
+	x *big.Float
+}
+
+// exact returns the exact value of the number.
+func exact[T Number](v T) value {
+	typ := reflect.TypeFor[T]()
+	rv := reflect.ValueOf(v)
+
+	switch {
+	case isFloat(typ) && math.IsNaN(rv.Float()):
+		return value{}
+	case isFloat(typ):
+		return value{new(big.Float).SetFloat64(rv.Float())}
+	case rv.CanInt():
+		return value{new(big.Float).SetInt64(rv.Int())}
+	}
+	return value{new(big.Float).SetUint64(rv.Uint())}
+}
+
+// refConvert returns what Convert returns for the value.
+func refConvert[To Number](v value) (To, error) {
+	typ := reflect.TypeFor[To]()
+
+	if isFloat(typ) {
+		if v.x == nil || v.x.IsInf() {
+			return refWrap[To](v), nil
+		}
+		if typ.Bits() == 32 {
+			if new(big.Float).Abs(v.x).Cmp(big.NewFloat(math.MaxFloat32)) > 0 {
+				return 0, ErrOverflow
+			}
+			f, acc := v.x.Float32()
+			if acc != big.Exact {
+				return 0, ErrPrecision
+			}
+			return To(f), nil
+		}
+		f, acc := v.x.Float64()
+		if acc != big.Exact {
+			return 0, ErrPrecision
+		}
+		return To(f), nil
+	}
+
+	switch {
+	case v.x == nil:
+		return 0, ErrNaN
+	case v.x.IsInf():
+		return 0, ErrOverflow
+	}
+
+	i, _ := v.x.Int(nil)
+	lo, hi := limits(typ)
+	if i.Cmp(lo) < 0 || i.Cmp(hi) > 0 {
+		return 0, ErrOverflow
+	}
+	if !v.x.IsInt() {
+		return 0, ErrFraction
+	}
+	return fromBig[To](i), nil
+}
+
+// refSaturate returns what Saturate returns for the value.
+func refSaturate[To Number](v value) To {
+	typ := reflect.TypeFor[To]()
+
+	if isFloat(typ) {
+		if typ.Bits() == 32 && v.x != nil && !v.x.IsInf() {
+			if m := big.NewFloat(math.MaxFloat32); new(big.Float).Abs(v.x).Cmp(m) > 0 {
+				return To(math.MaxFloat32 * float64(v.x.Sign()))
+			}
+		}
+		return refWrap[To](v)
+	}
+
+	if v.x == nil {
+		return 0
+	}
+
+	lo, hi := limits(typ)
+	switch {
+	case v.x.Cmp(new(big.Float).SetInt(lo)) < 0:
+		return fromBig[To](lo)
+	case v.x.Cmp(new(big.Float).SetInt(hi)) > 0:
+		return fromBig[To](hi)
+	}
+	i, _ := v.x.Int(nil)
+	return fromBig[To](i)
+}
+
+// refWrap returns what Wrap returns for the value.
+func refWrap[To Number](v value) To {
+	typ := reflect.TypeFor[To]()
+
+	if isFloat(typ) {
+		switch {
+		case v.x == nil:
+			return To(math.NaN())
+		case typ.Bits() == 32:
+			f, _ := v.x.Float32()
+			return To(f)
+		}
+		f, _ := v.x.Float64()
+		return To(f)
+	}
+
+	if v.x == nil || v.x.IsInf() {
+		return 0
+	}
+
+	// Keep the low bits of the two's complement of the integer part.
+	i, _ := v.x.Int(nil)
+	mod := new(big.Int).Lsh(big.NewInt(1), uint(typ.Bits()))
+	i.Mod(i, mod)
+
+	lo, hi := limits(typ)
+	if i.Cmp(hi) > 0 {
+		i.Sub(i, mod)
+	}
+	if i.Cmp(lo) < 0 {
+		panic("numconv: wrapped value below the limits")
+	}
+	return fromBig[To](i)
+}
+
+// limits returns the smallest and largest values of the integer type.
+func limits(typ reflect.Type) (*big.Int, *big.Int) {
+	bits := uint(typ.Bits())
+	one := big.NewInt(1)
+
+	if strings.HasPrefix(typ.Kind().String(), "uint") {
+		return big.NewInt(0), new(big.Int).Sub(new(big.Int).Lsh(one, bits), one)
+	}
+	return new(big.Int).Neg(new(big.Int).Lsh(one, bits-1)), new(big.Int).Sub(new(big.Int).Lsh(one, bits-1), one)
+}
+
+// fromBig converts an integer that fits in the integer type T.
+func fromBig[T Number](i *big.Int) T {
+	if i.Sign() < 0 {
+		return T(i.Int64())
+	}
+	return T(i.Uint64())
+}
+
+// isFloat reports whether the type is a float type.
+func isFloat(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64
+}
+
+// same reports whether the numbers are the same, treating NaN as the same
+// as NaN and telling zero from negative zero.
+func same[T Number](a T, b T) bool {
+	if a != a || b != b {
+		return a != a && b != b
+	}
+	if a == 0 && b == 0 {
+		return math.Signbit(float64(a)) == math.Signbit(float64(b))
+	}
+	return a == b
+}
@@ -0,0 +1,77 @@
+package pointergraph_test
+
+import (
+	"os"
+
+	"ultimate-go-programming/tools/pointergraph"
+)
+
+// Draw the values a set of variables point to. Save the output and render
+// it with dot -Tsvg graph.dot > graph.svg.
+func ExampleWriteDOT() {
+	type person struct {
+		name   string
+		email  string
+		logins int
+	}
+
+	// Declare a person and share it with two pointers: one to the whole
+	// value and one to a single field.
+	bill := person{
+		name:   "Bill",
+		email:  "bill@email.com",
+		logins: 3,
+	}
+	owner := &bill
+	logins := &bill.logins
+
+	// Declare a slice of persons and two slices that share its backing
+	// array. Each slice value is a header of its own, but the pointers in
+	// the headers point into the same array.
+	team := make([]person, 3, 4)
+	team[0] = bill
+	team[1] = person{name: "Lisa", email: "lisa@email.com"}
+	team[2] = person{name: "Ed", email: "ed@email.com", logins: 1}
+
+	leads := team[:2]
+	rest := team[1:]
+
+	// Both owner and logins point into bill, the three slices point into
+	// the same array and only the element each slice starts at differs.
+	pointergraph.WriteDOT(os.Stdout,
+		pointergraph.Root{Name: "bill", Value: &bill},
+		pointergraph.Root{Name: "owner", Value: owner},
+		pointergraph.Root{Name: "logins", Value: logins},
+		pointergraph.Root{Name: "team", Value: team},
+		pointergraph.Root{Name: "leads", Value: leads},
+		pointergraph.Root{Name: "rest", Value: rest},
+	)
+
+	// Output:
+	// digraph G {
+	// 	rankdir=LR;
+	// 	node [shape=record, fontname="monospace", fontsize=10];
+	// 	edge [fontname="monospace", fontsize=9];
+	//
+	// 	root0 [shape=box, style=rounded, label="bill"];
+	// 	root0 -> block0;
+	// 	root1 [shape=box, style=rounded, label="owner"];
+	// 	root1 -> block0;
+	// 	root2 [shape=box, style=rounded, label="logins"];
+	// 	root2 -> block0:f2;
+	// 	root3 [shape=box, style=rounded, label="team"];
+	// 	header1 [label="[]pointergraph_test.person | <p> ptr | len 3 | cap 4", style=filled, fillcolor="#eeeeee"];
+	// 	root3 -> header1;
+	// 	header1:p -> block1:e0;
+	// 	root4 [shape=box, style=rounded, label="leads"];
+	// 	header2 [label="[]pointergraph_test.person | <p> ptr | len 2 | cap 4", style=filled, fillcolor="#eeeeee"];
+	// 	root4 -> header2;
+	// 	header2:p -> block1:e0;
+	// 	root5 [shape=box, style=rounded, label="rest"];
+	// 	header3 [label="[]pointergraph_test.person | <p> ptr | len 2 | cap 3", style=filled, fillcolor="#eeeeee"];
+	// 	root5 -> header3;
+	// 	header3:p -> block1:e1;
+	// 	block0 [label="pointergraph_test.person | <f0> name: \"Bill\" | <f1> email: \"bill@email.com\" | <f2> logins: 3"];
+	// 	block1 [label="[4]pointergraph_test.person | <e0> [0] \{\"Bill\" \"bill@email.com\" 3\} | <e1> [1] \{\"Lisa\" \"lisa@email.com\" 0\} | <e2> [2] \{\"Ed\" \"ed@email.com\" 1\} | <e3> [3] \{\"\" \"\" 0\}"];
+	// }
+}
@@ -0,0 +1,543 @@
+// Package pointergraph walks the values reachable from a set of roots and
+// writes them as a Graphviz DOT graph.
+//
+// Every block of memory reached through a pointer or a slice becomes a node:
+// structs show their fields, backing arrays show their elements and pointers
+// become edges to the field or element they hold the address of. Slices are
+// drawn as header nodes with their length and capacity pointing into the
+// backing array, so two slices sharing an array point into the same node.
+//
+// Pointers that hold the address of a field, like &bill.logins, point into
+// the struct that holds the field. Pass roots as pointers so the graph knows
+// where they live; a root passed by value is a copy with no address of its
+// own.
+//
+// Render the output with dot:
+//
+//	dot -Tsvg graph.dot > graph.svg
+package pointergraph
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxElems is the number of elements of a backing array drawn when the
+// graph doesn't set a limit.
+const maxElems = 16
+
+// Root is a named starting point of the graph, usually a variable.
+type Root struct {
+	Name  string
+	Value any
+}
+
+// Graph collects the memory reachable from the roots.
+type Graph struct {
+	MaxElems int // Elements of a backing array to draw. Zero means 16.
+
+	roots   []Root
+	blocks  []*block
+	seen    map[visit]bool
+	maps    map[uintptr]*block
+	groups  []*group // Groups in memory order.
+	found   []*group // Groups in the order their first block was found.
+	headers int
+}
+
+// visit identifies a block of memory already collected.
+type visit struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// block is a value stored in memory: the target of a pointer, a backing
+// array or a map.
+type block struct {
+	id    string
+	found int // Number of blocks found before this one.
+	start uintptr
+	end   uintptr
+	array bool          // A backing array of elements of type typ.
+	typ   reflect.Type  // Type of the value or of the array elements.
+	value reflect.Value // The value, or a slice over the whole array.
+}
+
+// group is a range of memory covered by overlapping blocks. The
+// representative is the block drawn for all of them.
+type group struct {
+	start uintptr
+	end   uintptr
+	found int
+	rep   *block
+}
+
+// New constructs a graph of the values reachable from the roots.
+func New(roots ...Root) *Graph {
+	g := Graph{
+		roots: roots,
+	}
+	return &g
+}
+
+// WriteDOT writes the graph of the values reachable from the roots.
+func WriteDOT(w io.Writer, roots ...Root) error {
+	return New(roots...).WriteDOT(w)
+}
+
+// WriteDOT writes the graph in the DOT language. Every call walks the roots
+// again, so it draws the values as they are at the time of the call.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	g.reset()
+
+	var local []*block
+	for _, r := range g.roots {
+		v := reflect.ValueOf(r.Value)
+		if !v.IsValid() {
+			continue
+		}
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			g.collect(v)
+		default:
+			// The root is a copy so it gets a block without an address.
+			b := block{typ: v.Type(), value: v}
+			local = append(local, &b)
+			g.collect(v)
+		}
+	}
+
+	g.merge()
+
+	var out strings.Builder
+	out.WriteString("digraph G {\n")
+	out.WriteString("\trankdir=LR;\n")
+	out.WriteString("\tnode [shape=record, fontname=\"monospace\", fontsize=10];\n")
+	out.WriteString("\tedge [fontname=\"monospace\", fontsize=9];\n\n")
+
+	localIdx := 0
+	for i, r := range g.roots {
+		id := fmt.Sprintf("root%d", i)
+		fmt.Fprintf(&out, "\t%s [shape=box, style=rounded, label=%s];\n", id, strconv.Quote(r.Name))
+
+		v := reflect.ValueOf(r.Value)
+		if !v.IsValid() {
+			continue
+		}
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			g.edges(&out, id, v)
+		default:
+			b := local[localIdx]
+			localIdx++
+			b.id = fmt.Sprintf("value%d", localIdx)
+			g.writeBlock(&out, b)
+			fmt.Fprintf(&out, "\t%s -> %s;\n", id, b.id)
+		}
+	}
+
+	for _, gr := range g.found {
+		g.writeBlock(&out, gr.rep)
+	}
+	for _, b := range g.sortedMaps() {
+		g.writeBlock(&out, b)
+	}
+
+	out.WriteString("}\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// reset forgets the blocks collected by an earlier call to WriteDOT.
+func (g *Graph) reset() {
+	g.blocks = nil
+	g.seen = make(map[visit]bool)
+	g.maps = make(map[uintptr]*block)
+	g.groups = nil
+	g.found = nil
+	g.headers = 0
+}
+
+// =============================================================================
+
+// collect walks the value and records every block of memory it reaches.
+func (g *Graph) collect(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || v.Type().Elem().Size() == 0 {
+			return
+		}
+		elem := v.Elem()
+		key := visit{v.Pointer(), elem.Type()}
+		if g.seen[key] {
+			return
+		}
+		g.seen[key] = true
+
+		g.blocks = append(g.blocks, &block{
+			found: len(g.blocks),
+			start: v.Pointer(),
+			end:   v.Pointer() + elem.Type().Size(),
+			typ:   elem.Type(),
+			value: elem,
+		})
+		g.collect(elem)
+
+	case reflect.Slice:
+		size := v.Type().Elem().Size()
+		if v.IsNil() || v.Cap() == 0 || size == 0 {
+			return
+		}
+		full := v.Slice(0, v.Cap())
+		key := visit{v.Pointer(), full.Type()}
+		if g.seen[key] {
+			return
+		}
+		g.seen[key] = true
+
+		g.blocks = append(g.blocks, &block{
+			found: len(g.blocks),
+			start: v.Pointer(),
+			end:   v.Pointer() + uintptr(v.Cap())*size,
+			array: true,
+			typ:   v.Type().Elem(),
+			value: full,
+		})
+		for i := range full.Len() {
+			g.collect(full.Index(i))
+		}
+
+	case reflect.Map:
+		if v.IsNil() || g.maps[v.Pointer()] != nil {
+			return
+		}
+		g.maps[v.Pointer()] = &block{
+			id:    fmt.Sprintf("map%d", len(g.maps)),
+			start: v.Pointer(),
+			typ:   v.Type(),
+			value: v,
+		}
+		for iter := v.MapRange(); iter.Next(); {
+			g.collect(iter.Key())
+			g.collect(iter.Value())
+		}
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			g.collect(v.Elem())
+		}
+
+	case reflect.Struct:
+		for i := range v.NumField() {
+			g.collect(v.Field(i))
+		}
+
+	case reflect.Array:
+		for i := range v.Len() {
+			g.collect(v.Index(i))
+		}
+	}
+}
+
+// merge groups the blocks that overlap in memory. A block inside another,
+// like a field inside its struct, is drawn as part of the larger one. Slices
+// of the same backing array that overlap without one containing the other
+// are drawn as a single array covering both.
+func (g *Graph) merge() {
+	sort.Slice(g.blocks, func(i, j int) bool {
+		a, b := g.blocks[i], g.blocks[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		return a.end-a.start > b.end-b.start
+	})
+
+	var members [][]*block
+	for _, b := range g.blocks {
+		n := len(g.groups)
+		if n > 0 && b.start < g.groups[n-1].end {
+			gr := g.groups[n-1]
+			gr.end = max(gr.end, b.end)
+			gr.found = min(gr.found, b.found)
+			members[n-1] = append(members[n-1], b)
+			continue
+		}
+		g.groups = append(g.groups, &group{start: b.start, end: b.end, found: b.found})
+		members = append(members, []*block{b})
+	}
+
+	for i, gr := range g.groups {
+		gr.rep = representative(gr, members[i])
+	}
+
+	// Groups are numbered in the order they were found, not the order
+	// they happen to be in memory, so the graph reads the same every time.
+	g.found = append([]*group(nil), g.groups...)
+	sort.Slice(g.found, func(i, j int) bool {
+		return g.found[i].found < g.found[j].found
+	})
+	for i, gr := range g.found {
+		gr.rep.id = fmt.Sprintf("block%d", i)
+	}
+}
+
+// representative returns the block to draw for the group.
+func representative(gr *group, members []*block) *block {
+	for _, b := range members {
+		if b.start == gr.start && b.end == gr.end {
+			return b
+		}
+	}
+
+	// Slices over the same array only ever overlap partially through
+	// their capacities, so the array is rebuilt over the whole range.
+	first := members[0]
+	for _, b := range members {
+		if !b.array || b.typ != first.typ {
+			return first
+		}
+	}
+
+	// The first member starts where the group starts.
+	n := int((gr.end - gr.start) / first.typ.Size())
+	b := block{
+		start: gr.start,
+		end:   gr.end,
+		array: true,
+		typ:   first.typ,
+		value: reflect.SliceAt(first.typ, first.value.UnsafePointer(), n),
+	}
+	return &b
+}
+
+// sortedMaps returns the map blocks in the order they were found.
+func (g *Graph) sortedMaps() []*block {
+	list := make([]*block, 0, len(g.maps))
+	for _, b := range g.maps {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(list[i].id, "map"))
+		b, _ := strconv.Atoi(strings.TrimPrefix(list[j].id, "map"))
+		return a < b
+	})
+	return list
+}
+
+// resolve returns the node and port that hold the address of a value of
+// the type. A pointer to a whole block points to the node itself.
+func (g *Graph) resolve(addr uintptr, typ reflect.Type) (string, bool) {
+	i := sort.Search(len(g.groups), func(i int) bool { return g.groups[i].end > addr })
+	if i == len(g.groups) || g.groups[i].start > addr {
+		return "", false
+	}
+
+	b := g.groups[i].rep
+	off := addr - b.start
+	if off == 0 && !b.array && b.typ == typ {
+		return b.id, true
+	}
+
+	switch {
+	case b.array:
+		idx := int(off / b.typ.Size())
+		return fmt.Sprintf("%s:e%d", b.id, min(idx, g.limit(b.value.Len()))), true
+
+	case b.typ.Kind() == reflect.Struct:
+		for f := range b.typ.NumField() {
+			field := b.typ.Field(f)
+			if off >= field.Offset && off < field.Offset+max(field.Type.Size(), 1) {
+				return fmt.Sprintf("%s:f%d", b.id, f), true
+			}
+		}
+	}
+
+	return b.id, true
+}
+
+// limit returns how many elements of an array are drawn.
+func (g *Graph) limit(n int) int {
+	if g.MaxElems > 0 {
+		return min(n, g.MaxElems)
+	}
+	return min(n, maxElems)
+}
+
+// =============================================================================
+
+// writeBlock writes the node for the block followed by the edges leaving it.
+func (g *Graph) writeBlock(out *strings.Builder, b *block) {
+	var cells []string
+	var edges strings.Builder
+
+	switch {
+	case b.array:
+		n := b.value.Len()
+		cells = append(cells, escape(fmt.Sprintf("[%d]%s", n, b.typ)))
+		shown := g.limit(n)
+		for i := range shown {
+			port := fmt.Sprintf("e%d", i)
+			cells = append(cells, fmt.Sprintf("<%s> %s", port, escape(fmt.Sprintf("[%d] %s", i, short(b.value.Index(i))))))
+			g.edges(&edges, b.id+":"+port, b.value.Index(i))
+		}
+		if shown < n {
+			cells = append(cells, fmt.Sprintf("<e%d> %s", shown, escape(fmt.Sprintf("... %d more", n-shown))))
+		}
+
+	case b.typ.Kind() == reflect.Map:
+		cells = append(cells, escape(b.typ.String()))
+		keys := b.value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return short(keys[i]) < short(keys[j]) })
+		for i, k := range keys {
+			port := fmt.Sprintf("k%d", i)
+			v := b.value.MapIndex(k)
+			cells = append(cells, fmt.Sprintf("<%s> %s", port, escape(short(k)+": "+short(v))))
+			g.edges(&edges, b.id+":"+port, k)
+			g.edges(&edges, b.id+":"+port, v)
+		}
+
+	case b.typ.Kind() == reflect.Struct:
+		cells = append(cells, escape(b.typ.String()))
+		for i := range b.typ.NumField() {
+			port := fmt.Sprintf("f%d", i)
+			f := b.value.Field(i)
+			cells = append(cells, fmt.Sprintf("<%s> %s", port, escape(b.typ.Field(i).Name+": "+short(f))))
+			g.edges(&edges, b.id+":"+port, f)
+		}
+
+	default:
+		cells = append(cells, escape(b.typ.String()), "<v> "+escape(short(b.value)))
+		g.edges(&edges, b.id+":v", b.value)
+	}
+
+	fmt.Fprintf(out, "\t%s [label=\"%s\"];\n", b.id, strings.Join(cells, " | "))
+	out.WriteString(edges.String())
+}
+
+// edges writes an edge from the port for every pointer, slice and map held
+// directly by the value, including those inside struct fields and array
+// elements.
+func (g *Graph) edges(out *strings.Builder, from string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if to, ok := g.resolve(v.Pointer(), v.Type().Elem()); ok {
+			fmt.Fprintf(out, "\t%s -> %s;\n", from, to)
+		}
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+
+		// Every slice value gets its own header node.
+		g.headers++
+		id := fmt.Sprintf("header%d", g.headers)
+		label := fmt.Sprintf("%s | <p> ptr | len %d | cap %d", escape(v.Type().String()), v.Len(), v.Cap())
+		fmt.Fprintf(out, "\t%s [label=\"%s\", style=filled, fillcolor=\"#eeeeee\"];\n", id, label)
+		fmt.Fprintf(out, "\t%s -> %s;\n", from, id)
+
+		if v.Cap() == 0 {
+			return
+		}
+		if to, ok := g.resolve(v.Pointer(), v.Type().Elem()); ok {
+			fmt.Fprintf(out, "\t%s:p -> %s;\n", id, to)
+		}
+
+	case reflect.Map:
+		if b := g.maps[v.Pointer()]; b != nil && !v.IsNil() {
+			fmt.Fprintf(out, "\t%s -> %s;\n", from, b.id)
+		}
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			g.edges(out, from, v.Elem())
+		}
+
+	case reflect.Struct:
+		for i := range v.NumField() {
+			g.edges(out, from, v.Field(i))
+		}
+
+	case reflect.Array:
+		for i := range v.Len() {
+			g.edges(out, from, v.Index(i))
+		}
+	}
+}
+
+// short returns a compact form of the value for a node label.
+func short(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.String:
+		return strconv.Quote(truncate(v.String(), 24))
+	case reflect.Pointer, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return fmt.Sprintf("%#x", v.Pointer())
+	case reflect.Slice:
+		if v.IsNil() {
+			return "nil"
+		}
+		return fmt.Sprintf("len %d cap %d", v.Len(), v.Cap())
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return short(v.Elem())
+	case reflect.Struct:
+		parts := make([]string, v.NumField())
+		for i := range parts {
+			parts[i] = short(v.Field(i))
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", v.Len(), v.Type().Elem())
+	}
+	return v.Type().String()
+}
+
+// truncate cuts the string to at most n runes, ending it with ... when it
+// is cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	i := 0
+	for range n - 3 {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return s[:i] + "..."
+}
+
+// escape escapes the characters that have a meaning in record labels.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '{', '}', '|', '<', '>', '"', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
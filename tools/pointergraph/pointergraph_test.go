@@ -0,0 +1,211 @@
+package pointergraph
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type person struct {
+	name   string
+	logins int
+}
+
+type node struct {
+	value int
+	next  *node
+}
+
+func TestWriteDOT(t *testing.T) {
+	bill := person{name: "Bill", logins: 3}
+
+	team := make([]person, 2, 3)
+	team[0] = person{name: "Lisa"}
+	team[1] = person{name: "Ed", logins: 1}
+
+	first := &node{value: 1}
+	first.next = &node{value: 2, next: first}
+
+	limited := New(Root{Name: "n", Value: []int{1, 2, 3, 4}})
+	limited.MaxElems = 2
+
+	tests := []struct {
+		name  string
+		graph *Graph
+		want  string
+	}{
+		{
+			name:  "field",
+			graph: New(Root{Name: "bill", Value: &bill}, Root{Name: "logins", Value: &bill.logins}),
+			want: `	root0 [shape=box, style=rounded, label="bill"];
+	root0 -> block0;
+	root1 [shape=box, style=rounded, label="logins"];
+	root1 -> block0:f1;
+	block0 [label="pointergraph.person | <f0> name: \"Bill\" | <f1> logins: 3"];
+`,
+		},
+		{
+			name:  "shared array",
+			graph: New(Root{Name: "team", Value: team}, Root{Name: "rest", Value: team[1:]}),
+			want: `	root0 [shape=box, style=rounded, label="team"];
+	header1 [label="[]pointergraph.person | <p> ptr | len 2 | cap 3", style=filled, fillcolor="#eeeeee"];
+	root0 -> header1;
+	header1:p -> block0:e0;
+	root1 [shape=box, style=rounded, label="rest"];
+	header2 [label="[]pointergraph.person | <p> ptr | len 1 | cap 2", style=filled, fillcolor="#eeeeee"];
+	root1 -> header2;
+	header2:p -> block0:e1;
+	block0 [label="[3]pointergraph.person | <e0> [0] \{\"Lisa\" 0\} | <e1> [1] \{\"Ed\" 1\} | <e2> [2] \{\"\" 0\}"];
+`,
+		},
+		{
+			name:  "limit",
+			graph: limited,
+			want: `	root0 [shape=box, style=rounded, label="n"];
+	header1 [label="[]int | <p> ptr | len 4 | cap 4", style=filled, fillcolor="#eeeeee"];
+	root0 -> header1;
+	header1:p -> block0:e0;
+	block0 [label="[4]int | <e0> [0] 1 | <e1> [1] 2 | <e2> ... 2 more"];
+`,
+		},
+		{
+			name:  "cycle",
+			graph: New(Root{Name: "first", Value: first}),
+			want: `	root0 [shape=box, style=rounded, label="first"];
+	root0 -> block0;
+	block0 [label="pointergraph.node | <f0> value: 1 | <f1> next: *"];
+	block0:f1 -> block1;
+	block1 [label="pointergraph.node | <f0> value: 2 | <f1> next: *"];
+	block1:f1 -> block0;
+`,
+		},
+		{
+			name:  "map",
+			graph: New(Root{Name: "m", Value: map[string]*person{"b": &bill, "a": nil}}),
+			want: `	root0 [shape=box, style=rounded, label="m"];
+	root0 -> map0;
+	block0 [label="pointergraph.person | <f0> name: \"Bill\" | <f1> logins: 3"];
+	map0 [label="map[string]*pointergraph.person | <k0> \"a\": nil | <k1> \"b\": *"];
+	map0:k1 -> block0;
+`,
+		},
+		{
+			name:  "value",
+			graph: New(Root{Name: "bill", Value: bill}),
+			want: `	root0 [shape=box, style=rounded, label="bill"];
+	value1 [label="pointergraph.person | <f0> name: \"Bill\" | <f1> logins: 3"];
+	root0 -> value1;
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tt.graph.WriteDOT(&b); err != nil {
+				t.Fatal(err)
+			}
+
+			got := maskAddrs(b.String())
+			want := header + tt.want + "}\n"
+			if got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestWriteDOTStable checks that the blocks are numbered the same way no
+// matter where they happen to be in memory.
+func TestWriteDOTStable(t *testing.T) {
+	var want string
+	for i := range 20 {
+		a, b := new(person), new(person)
+		var buf strings.Builder
+		if i%2 == 0 {
+			WriteDOT(&buf, Root{Name: "a", Value: a}, Root{Name: "b", Value: b})
+		} else {
+			WriteDOT(&buf, Root{Name: "a", Value: b}, Root{Name: "b", Value: a})
+		}
+
+		got := maskAddrs(buf.String())
+		if i == 0 {
+			want = got
+		}
+		if got != want {
+			t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+func TestWriteDOTTwice(t *testing.T) {
+	bill := person{name: "Bill"}
+	team := []person{bill, {name: "Lisa"}}
+	g := New(Root{Name: "bill", Value: &bill}, Root{Name: "team", Value: team}, Root{Name: "rest", Value: team[1:]})
+
+	var first, second strings.Builder
+	if err := g.WriteDOT(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.WriteDOT(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("second call got:\n%s\nfirst call got:\n%s", second.String(), first.String())
+	}
+
+	// A value changed between the calls is drawn as it is now.
+	bill.logins = 7
+	var third strings.Builder
+	if err := g.WriteDOT(&third); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(third.String(), "logins: 7") {
+		t.Errorf("third call doesn't show the change:\n%s", third.String())
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"short", "short"},
+		{"exactly six", "exactly six"},
+		{"a longer string", "a longer..."},
+		{"\u4e16\u754c\u4e16\u754c\u4e16\u754c\u4e16\u754c\u4e16\u754c\u4e16\u754c", "\u4e16\u754c\u4e16\u754c\u4e16\u754c\u4e16\u754c..."},
+	}
+
+	for _, tt := range tests {
+		got := truncate(tt.s, 11)
+		if got != tt.want {
+			t.Errorf("truncate(%q, 11) = %q, want %q", tt.s, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, 11) = %q splits a rune", tt.s, got)
+		}
+	}
+}
+
+// header is the start of every graph.
+const header = `digraph G {
+	rankdir=LR;
+	node [shape=record, fontname="monospace", fontsize=10];
+	edge [fontname="monospace", fontsize=9];
+
+`
+
+// maskAddrs replaces every address in the text with a *.
+func maskAddrs(text string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, "0x")
+		if i < 0 {
+			break
+		}
+		b.WriteString(text[:i] + "*")
+		text = strings.TrimLeft(text[i+2:], "0123456789abcdef")
+	}
+	b.WriteString(text)
+	return b.String()
+}
@@ -0,0 +1,310 @@
+// Package promotion explores which fields and methods of a struct type are
+// reachable through embedding.
+//
+// The selector rules from the spec decide what a name refers to. For a name
+// x, the selector picks the member at the shallowest embedding depth. A
+// member declared deeper is shadowed by it, which is how superadmin.notify
+// hides the notify method promoted from user. When more than one member
+// with the name exists at the shallowest depth, or a single one is reached
+// through two embedding paths, the selector is ambiguous and using it is a
+// compile error.
+package promotion
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Set of states a member can be in.
+const (
+	Declared  = "declared"
+	Promoted  = "promoted"
+	Shadowed  = "shadowed"
+	Ambiguous = "ambiguous"
+)
+
+// Member is a field or method reachable from the explored type.
+type Member struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	Type       string   `json:"type"`
+	Depth      int      `json:"depth"`
+	Via        []string `json:"via,omitempty"`
+	Provider   string   `json:"provider"`
+	Status     string   `json:"status"`
+	ShadowedBy string   `json:"shadowed_by,omitempty"`
+	Embedded   bool     `json:"embedded,omitempty"`
+
+	// InValueSet is only meaningful for methods. It reports whether the
+	// method belongs to the method set of T and not just *T.
+	InValueSet bool `json:"in_value_set,omitempty"`
+
+	key       string
+	multiples bool
+}
+
+// Selector returns the full selector path used to reach the member.
+func (m Member) Selector(typ string) string {
+	parts := append([]string{typ}, m.Via...)
+	return strings.Join(append(parts, m.Name), ".")
+}
+
+// Report lists every member reachable from a struct type.
+type Report struct {
+	Type    string   `json:"type"`
+	Members []Member `json:"members"`
+}
+
+// embedded is a type waiting to be explored at the next depth. Multiples
+// is set when the type is reached through more than one path at the same
+// depth, which makes every member found through it ambiguous.
+type embedded struct {
+	typ       types.Type
+	via       []string
+	indirect  bool
+	multiples bool
+}
+
+// Explore walks the struct type breadth first through its embedded fields
+// and classifies every member it finds.
+func Explore(named *types.Named) (*Report, error) {
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, fmt.Errorf("%s is not a struct type", named.Obj().Name())
+	}
+
+	qual := func(p *types.Package) string {
+		if p == named.Obj().Pkg() {
+			return ""
+		}
+		return p.Name()
+	}
+
+	var members []Member
+	seen := make(map[*types.Named]bool)
+	current := []embedded{{typ: named}}
+
+	for depth := 0; len(current) > 0; depth++ {
+		var next []embedded
+
+		for _, e := range consolidate(current) {
+			if n, ok := e.typ.(*types.Named); ok {
+				if seen[n] {
+					continue
+				}
+				seen[n] = true
+
+				for i := range n.NumMethods() {
+					m := n.Method(i)
+					_, ptr := m.Type().(*types.Signature).Recv().Type().(*types.Pointer)
+
+					provider := types.TypeString(n, qual)
+					if ptr {
+						provider = "*" + provider
+					}
+
+					members = append(members, Member{
+						Name:       m.Name(),
+						Kind:       "method",
+						Type:       signature(m, qual),
+						Depth:      depth,
+						Via:        e.via,
+						Provider:   provider,
+						InValueSet: !ptr || e.indirect,
+						key:        key(m),
+						multiples:  e.multiples,
+					})
+				}
+			}
+
+			switch u := e.typ.Underlying().(type) {
+			case *types.Struct:
+				for i := range u.NumFields() {
+					f := u.Field(i)
+					members = append(members, Member{
+						Name:      f.Name(),
+						Kind:      "field",
+						Type:      types.TypeString(f.Type(), qual),
+						Depth:     depth,
+						Via:       e.via,
+						Provider:  types.TypeString(e.typ, qual),
+						Embedded:  f.Embedded(),
+						key:       key(f),
+						multiples: e.multiples,
+					})
+
+					if !f.Embedded() {
+						continue
+					}
+
+					ft, indirect := f.Type(), e.indirect
+					if p, ok := ft.(*types.Pointer); ok {
+						ft, indirect = p.Elem(), true
+					}
+
+					via := append(append([]string(nil), e.via...), f.Name())
+					next = append(next, embedded{typ: ft, via: via, indirect: indirect, multiples: e.multiples})
+				}
+
+			case *types.Interface:
+				for i := range u.NumMethods() {
+					m := u.Method(i)
+					members = append(members, Member{
+						Name:       m.Name(),
+						Kind:       "method",
+						Type:       signature(m, qual),
+						Depth:      depth,
+						Via:        e.via,
+						Provider:   types.TypeString(e.typ, qual),
+						InValueSet: true,
+						key:        key(m),
+						multiples:  e.multiples,
+					})
+				}
+			}
+		}
+
+		current = next
+	}
+
+	classify(named.Obj().Name(), members)
+
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].Depth != members[j].Depth {
+			return members[i].Depth < members[j].Depth
+		}
+		if members[i].Kind != members[j].Kind {
+			return members[i].Kind < members[j].Kind
+		}
+		return members[i].Name < members[j].Name
+	})
+
+	r := Report{
+		Type:    types.TypeString(named, func(p *types.Package) string { return p.Name() }),
+		Members: members,
+	}
+
+	return &r, nil
+}
+
+// consolidate merges the entries of a depth that reach the same named type
+// into the first of them and marks it as reached more than once, the way
+// the compiler looks up a selector.
+func consolidate(list []embedded) []embedded {
+	var out []embedded
+	index := make(map[*types.Named]int)
+
+	for _, e := range list {
+		n, ok := e.typ.(*types.Named)
+		if !ok {
+			out = append(out, e)
+			continue
+		}
+
+		if i, ok := index[n]; ok {
+			out[i].multiples = true
+			continue
+		}
+		index[n] = len(out)
+		out = append(out, e)
+	}
+
+	return out
+}
+
+// classify sets the status of every member by applying the selector rules
+// to the members that share a name.
+func classify(typ string, members []Member) {
+	byKey := make(map[string][]int)
+	for i, m := range members {
+		byKey[m.key] = append(byKey[m.key], i)
+	}
+
+	for _, idx := range byKey {
+		shallow := members[idx[0]].Depth
+		var winners []int
+		for _, i := range idx {
+			switch d := members[i].Depth; {
+			case d < shallow:
+				shallow, winners = d, []int{i}
+			case d == shallow:
+				winners = append(winners, i)
+			}
+		}
+
+		// A single member is still ambiguous when its type is reached
+		// through more than one path.
+		ambiguous := len(winners) > 1 || members[winners[0]].multiples
+
+		for _, i := range idx {
+			m := &members[i]
+
+			switch {
+			case m.Depth == shallow && ambiguous:
+				m.Status = Ambiguous
+			case m.Depth == shallow && m.Depth == 0:
+				m.Status = Declared
+			case m.Depth == shallow:
+				m.Status = Promoted
+			default:
+				m.Status = Shadowed
+				if !ambiguous {
+					m.ShadowedBy = members[winners[0]].Selector(typ)
+				} else {
+					m.ShadowedBy = fmt.Sprintf("ambiguous members at depth %d", shallow)
+				}
+			}
+		}
+	}
+}
+
+// WriteTable writes the report as an aligned terminal table.
+func (r *Report) WriteTable(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, r.Type); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tKIND\tDEPTH\tVIA\tPROVIDER\tSTATUS")
+
+	for _, m := range r.Members {
+		kind := m.Kind
+		if m.Embedded {
+			kind = "embedded field"
+		}
+
+		via := "-"
+		if len(m.Via) > 0 {
+			via = strings.Join(m.Via, ".")
+		}
+
+		status := m.Status
+		switch {
+		case m.Status == Shadowed:
+			status += " by " + m.ShadowedBy
+		case m.Kind == "method" && !m.InValueSet:
+			status += " (*T method set only)"
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%s\t%s\t%s\n", m.Name, kind, m.Depth, via, m.Provider, status)
+	}
+
+	return tw.Flush()
+}
+
+// key returns the identity used to compare member names. Unexported names
+// from different packages never collide.
+func key(obj types.Object) string {
+	if obj.Exported() || obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// signature returns the method signature without the func keyword.
+func signature(m *types.Func, qual types.Qualifier) string {
+	return strings.TrimPrefix(types.TypeString(m.Type(), qual), "func")
+}
@@ -0,0 +1,144 @@
+package promotion
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// explore type-checks the source and explores the named type.
+func explore(t *testing.T, src string, name string) *Report {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "src.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Explore(pkg.Scope().Lookup(name).Type().(*types.Named))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// statuses returns the status of every member by selector.
+func statuses(r *Report, typ string) map[string]string {
+	m := make(map[string]string)
+	for _, mem := range r.Members {
+		m[mem.Selector(typ)] = mem.Status
+	}
+	return m
+}
+
+func TestExplore(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]string
+	}{
+		{
+			name: "shadowed",
+			src: `package p
+type user struct{ name string }
+func (user) notify() {}
+type admin struct {
+	user
+	level string
+}
+func (admin) notify() {}
+`,
+			want: map[string]string{
+				"admin.user":        Declared,
+				"admin.level":       Declared,
+				"admin.notify":      Declared,
+				"admin.user.name":   Promoted,
+				"admin.user.notify": Shadowed,
+			},
+		},
+		{
+			name: "same depth",
+			src: `package p
+type A struct{ X int }
+type B struct{ X int; Y int }
+type admin struct {
+	A
+	B
+}
+`,
+			want: map[string]string{
+				"admin.A":   Declared,
+				"admin.B":   Declared,
+				"admin.A.X": Ambiguous,
+				"admin.B.X": Ambiguous,
+				"admin.B.Y": Promoted,
+			},
+		},
+		{
+			name: "diamond",
+			src: `package p
+type C struct{ X int }
+func (C) M() {}
+type A struct{ C }
+type B struct{ C }
+type admin struct {
+	A
+	B
+}
+`,
+			want: map[string]string{
+				"admin.A":     Declared,
+				"admin.B":     Declared,
+				"admin.A.C":   Ambiguous,
+				"admin.B.C":   Ambiguous,
+				"admin.A.C.X": Ambiguous,
+				"admin.A.C.M": Ambiguous,
+			},
+		},
+		{
+			name: "diamond shadowed",
+			src: `package p
+type C struct{ X int }
+type A struct{ C }
+type B struct{ C }
+type admin struct {
+	A
+	B
+	X string
+}
+`,
+			want: map[string]string{
+				"admin.A":     Declared,
+				"admin.B":     Declared,
+				"admin.X":     Declared,
+				"admin.A.C":   Ambiguous,
+				"admin.B.C":   Ambiguous,
+				"admin.A.C.X": Shadowed,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statuses(explore(t, tt.src, "admin"), "admin")
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d members %v, want %d", len(got), got, len(tt.want))
+			}
+			for sel, want := range tt.want {
+				if got[sel] != want {
+					t.Errorf("%s is %q, want %q", sel, got[sel], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package quantity_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"ultimate-go-programming/tools/quantity"
+)
+
+// Like time.Duration, every quantity is its own type with constants for its
+// units, so units of different quantities can't be mixed.
+func Example() {
+	// Untyped constants take the type of the unit they multiply.
+	cache := 64 * quantity.MiB
+	cache += 512 * quantity.KiB
+	fmt.Println("cache:", cache)
+
+	// invalid operation: cache + 3 * quantity.Metre (mismatched types quantity.ByteSize and quantity.Length)
+	// total := cache + 3*quantity.Metre
+
+	// Values of type int need an explicit conversion, like the grams in
+	// the Weight of a toy.
+	type toy struct {
+		Name   string
+		Weight int
+	}
+
+	t := toy{Name: "Monster Truck", Weight: 1500}
+	weight := quantity.Mass(t.Weight) * quantity.Gram
+	fmt.Println(t.Name, "weighs", weight)
+
+	// Output:
+	// cache: 64.5MiB
+	// Monster Truck weighs 1.5kg
+}
+
+// Parse reads back what String writes.
+func ExampleParseByteSize() {
+	disk, err := quantity.ParseByteSize("1.5GiB")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("parsed:", disk, int64(disk), "bytes")
+
+	if _, err := quantity.ParseLength("3 parsecs"); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// parsed: 1.5GiB 1610612736 bytes
+	// quantity: unknown unit "parsecs" in "3 parsecs"
+}
+
+// Quantities are marshaled as text and unmarshaled from text or from a
+// number of base units.
+func Example_json() {
+	type shipment struct {
+		Weight   quantity.Mass     `json:"weight"`
+		Distance quantity.Length   `json:"distance"`
+		Label    quantity.ByteSize `json:"label"`
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.Encode(shipment{1500 * quantity.Gram, 12*quantity.Kilometre + 300*quantity.Metre, 2 * quantity.KiB})
+
+	var s shipment
+	if err := json.Unmarshal([]byte(`{"weight":"3kg 200g","distance":"1.2m","label":4096}`), &s); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%+v\n", s)
+
+	// Output:
+	// {"weight":"1kg500g","distance":"12km300m","label":"2KiB"}
+	// {Weight:3.2kg Distance:1.2m Label:4KiB}
+}
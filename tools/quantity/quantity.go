@@ -0,0 +1,244 @@
+// Package quantity defines typed quantities the way the time package defines
+// time.Duration: a named integer type counting a base unit, with typed
+// constants for the other units.
+//
+// Every quantity is its own type, so a ByteSize can't be added to a Length
+// or passed where a Mass is expected without an explicit conversion, while
+// untyped constants still work the way 5*time.Second does:
+//
+//	limit := 64 * quantity.MiB
+//	limit += 3 * quantity.Metre // mismatched types ByteSize and Length
+//
+// A System holds the units of a quantity and does the formatting and
+// parsing for its type. String picks the largest unit the value reaches, so
+// 1610612736 bytes reads as 1.5GiB, and Parse reads the same text back.
+// String drops the digits past the second decimal, so a quantity is
+// marshaled with FormatExact instead, in as many units as it takes, like
+// 1KiB1B. It can be unmarshaled from any text Parse reads or from a number
+// of base units.
+package quantity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Quantity is the set of types a quantity can be declared with: an integer
+// number of its base unit.
+type Quantity interface {
+	~int64
+}
+
+// Unit is a named multiple of the base unit of a quantity.
+type Unit[Q Quantity] struct {
+	Symbol string
+	Size   Q
+}
+
+// System is the set of units of a quantity.
+type System[Q Quantity] struct {
+	Units   []Unit[Q] // Units used to format values, largest first. The last one is the base unit.
+	Aliases []Unit[Q] // Other units Parse accepts.
+}
+
+// Format returns the value in the largest unit it reaches, with at most two
+// decimals, like 1.5GiB. Values that need more decimals lose them, so they
+// don't parse back exactly; FormatExact keeps them.
+func (s System[Q]) Format(q Q) string {
+	u := s.Units[len(s.Units)-1]
+	mag := math.Abs(float64(q))
+	for _, unit := range s.Units {
+		if mag >= float64(unit.Size) {
+			u = unit
+			break
+		}
+	}
+
+	if q%u.Size == 0 {
+		return strconv.FormatInt(int64(q/u.Size), 10) + u.Symbol
+	}
+
+	// The digits past the second decimal are dropped rather than rounded
+	// so a value never reads as the next unit up.
+	v := math.Trunc(float64(q)/float64(u.Size)*100) / 100
+	text := strconv.FormatFloat(v, 'f', 2, 64)
+	text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	return text + u.Symbol
+}
+
+// FormatExact returns the value as a sum of units, largest first, like
+// 1KiB1B or 1m23cm4mm567µm, which Parse reads back as the same value.
+func (s System[Q]) FormatExact(q Q) string {
+	if q == 0 {
+		return "0" + s.Units[len(s.Units)-1].Symbol
+	}
+
+	var b strings.Builder
+	mag := uint64(q)
+	if q < 0 {
+		b.WriteString("-")
+		mag = -mag
+	}
+
+	for _, u := range s.Units {
+		if n := mag / uint64(u.Size); n > 0 {
+			b.WriteString(strconv.FormatUint(n, 10) + u.Symbol)
+			mag -= n * uint64(u.Size)
+		}
+	}
+
+	return b.String()
+}
+
+// Parse reads a value like "1.5GiB" or "3kg 200g". The value is a sequence
+// of decimal numbers each followed by a unit, with an optional sign in front
+// and optional spaces in between. A lone 0 needs no unit.
+func (s System[Q]) Parse(text string) (Q, error) {
+	in := strings.TrimSpace(text)
+
+	neg := false
+	switch {
+	case strings.HasPrefix(in, "-"):
+		neg = true
+		in = in[1:]
+	case strings.HasPrefix(in, "+"):
+		in = in[1:]
+	}
+
+	if in == "0" {
+		return 0, nil
+	}
+	if in == "" {
+		return 0, fmt.Errorf("quantity: invalid value %q", text)
+	}
+
+	var total uint64
+	for in != "" {
+		in = strings.TrimLeft(in, " ")
+
+		// The number runs up to the first character that isn't a digit
+		// or a decimal point.
+		end := strings.IndexFunc(in, func(r rune) bool {
+			return r != '.' && (r < '0' || r > '9')
+		})
+		if end < 0 {
+			return 0, fmt.Errorf("quantity: missing unit in %q", text)
+		}
+		number := in[:end]
+		in = strings.TrimLeft(in[end:], " ")
+
+		// The unit runs up to the next number or space.
+		end = strings.IndexFunc(in, func(r rune) bool {
+			return r == ' ' || r == '.' || r >= '0' && r <= '9'
+		})
+		if end < 0 {
+			end = len(in)
+		}
+		symbol := in[:end]
+		in = in[end:]
+
+		unit, ok := s.lookup(symbol)
+		if !ok {
+			if symbol == "" {
+				return 0, fmt.Errorf("quantity: missing unit in %q", text)
+			}
+			return 0, fmt.Errorf("quantity: unknown unit %q in %q", symbol, text)
+		}
+
+		v, err := scale(number, uint64(unit.Size))
+		if err != nil {
+			return 0, fmt.Errorf("quantity: %w in %q", err, text)
+		}
+		if total += v; total < v || total > 1<<63 {
+			return 0, fmt.Errorf("quantity: %w in %q", errRange, text)
+		}
+	}
+
+	if neg {
+		return Q(-int64(total)), nil
+	}
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("quantity: %w in %q", errRange, text)
+	}
+	return Q(total), nil
+}
+
+// lookup returns the unit with the symbol. Symbols are matched exactly
+// first and then ignoring case, as long as that leaves a single size.
+func (s System[Q]) lookup(symbol string) (Unit[Q], bool) {
+	units := append(s.Units[:len(s.Units):len(s.Units)], s.Aliases...)
+	for _, u := range units {
+		if u.Symbol == symbol {
+			return u, true
+		}
+	}
+
+	// Symbols that only differ in case, like KB and kB, may name the
+	// same unit.
+	var found []Unit[Q]
+	for _, u := range units {
+		if strings.EqualFold(u.Symbol, symbol) && (len(found) == 0 || found[0].Size == u.Size) {
+			found = append(found, u)
+		} else if strings.EqualFold(u.Symbol, symbol) {
+			return Unit[Q]{}, false
+		}
+	}
+	if len(found) > 0 {
+		return found[0], true
+	}
+	return Unit[Q]{}, false
+}
+
+// ParseJSON reads a value that is either a JSON string parsed with
+// Parse or a JSON number of base units.
+func (s System[Q]) ParseJSON(data []byte) (Q, error) {
+	if len(data) > 0 && data[0] == '"' {
+		text, err := strconv.Unquote(string(data))
+		if err != nil {
+			return 0, fmt.Errorf("quantity: invalid JSON string %s", data)
+		}
+		return s.Parse(text)
+	}
+
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("quantity: invalid JSON value %s", data)
+	}
+	return Q(n), nil
+}
+
+// =============================================================================
+
+// errRange is the error for values that don't fit in a quantity.
+var errRange = errors.New("value out of range")
+
+// scale returns the decimal number multiplied by the size of its unit. A
+// fraction finer than the base unit is rounded to the nearest base unit.
+func scale(number string, size uint64) (uint64, error) {
+	whole, frac, _ := strings.Cut(number, ".")
+	if whole == "" && frac == "" || strings.Contains(frac, ".") {
+		return 0, fmt.Errorf("invalid number %q", number)
+	}
+
+	var v uint64
+	if whole != "" {
+		w, err := strconv.ParseUint(whole, 10, 64)
+		if err != nil || w > (1<<63)/size {
+			return 0, errRange
+		}
+		v = w * size
+	}
+
+	if frac != "" {
+		f, err := strconv.ParseFloat("0."+frac, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", number)
+		}
+		v += uint64(math.Round(f * float64(size)))
+	}
+
+	return v, nil
+}
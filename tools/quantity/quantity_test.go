@@ -0,0 +1,129 @@
+package quantity
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		str       string
+		exact     string
+		wantStr   string
+		wantExact string
+	}{
+		{"zero", ByteSize(0).String(), ByteSizes.FormatExact(0), "0B", "0B"},
+		{"bytes", ByteSize(512).String(), ByteSizes.FormatExact(512), "512B", "512B"},
+		{"unit", (64 * MiB).String(), ByteSizes.FormatExact(64 * MiB), "64MiB", "64MiB"},
+		{"fraction", (1536 * MiB).String(), ByteSizes.FormatExact(1536 * MiB), "1.5GiB", "1GiB512MiB"},
+		{"truncated", ByteSize(1025).String(), ByteSizes.FormatExact(1025), "1KiB", "1KiB1B"},
+		{"negative", (-3 * KiB / 2).String(), ByteSizes.FormatExact(-3 * KiB / 2), "-1.5KiB", "-1KiB512B"},
+		{"length", Length(1234567).String(), Lengths.FormatExact(1234567), "1.23m", "1m23cm4mm567µm"},
+		{"mass", (3*Kilogram + 200*Gram).String(), Masses.FormatExact(3*Kilogram + 200*Gram), "3.2kg", "3kg200g"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.str != tt.wantStr {
+				t.Errorf("String = %q, want %q", tt.str, tt.wantStr)
+			}
+			if tt.exact != tt.wantExact {
+				t.Errorf("FormatExact = %q, want %q", tt.exact, tt.wantExact)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+		err  bool
+	}{
+		{in: "0", want: 0},
+		{in: "1.5GiB", want: 1536 * MiB},
+		{in: "512MB", want: 512 * MB},
+		{in: "10kb", want: 10 * KB},
+		{in: "1KiB1B", want: 1025},
+		{in: "1KiB 1B", want: 1025},
+		{in: "-2KiB", want: -2 * KiB},
+		{in: "+2KiB", want: 2 * KiB},
+		{in: "0.5B", want: 1},
+		{in: "-8192PiB", want: math.MinInt64},
+		{in: "8192PiB", err: true},
+		{in: "", err: true},
+		{in: "12", err: true},
+		{in: "1XB", err: true},
+		{in: "1..2KiB", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("ParseByteSize(%q) = %d, want an error", tt.in, got)
+		case !tt.err && err != nil:
+			t.Errorf("ParseByteSize(%q): %v", tt.in, err)
+		case got != tt.want:
+			t.Errorf("ParseByteSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestMarshalRoundTrip marshals values that String rounds and checks they
+// unmarshal to the same value.
+func TestMarshalRoundTrip(t *testing.T) {
+	type record struct {
+		Size   ByteSize `json:"size"`
+		Length Length   `json:"length"`
+		Mass   Mass     `json:"mass"`
+	}
+
+	values := []int64{0, 1, -1, 999, 1000, 1023, 1024, 1025, 1000000, 1234567, 1610612737, math.MaxInt64, math.MinInt64}
+
+	r := rand.New(rand.NewSource(1))
+	for range 1000 {
+		values = append(values, r.Int63()>>r.Intn(63), -r.Int63()>>r.Intn(63))
+	}
+
+	for _, v := range values {
+		in := record{ByteSize(v), Length(v), Mass(v)}
+
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", v, err)
+		}
+
+		var out record
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+
+		if out != in {
+			t.Errorf("%d: %s unmarshals to %+v", v, data, out)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var s struct {
+		Weight   Mass     `json:"weight"`
+		Distance Length   `json:"distance"`
+		Label    ByteSize `json:"label"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"weight":"3kg 200g","distance":"1.2m","label":4096}`), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Weight != 3200*Gram || s.Distance != 120*Centimetre || s.Label != 4*KiB {
+		t.Errorf("got %v %v %v", s.Weight, s.Distance, s.Label)
+	}
+
+	if err := json.Unmarshal([]byte(`{"label":true}`), &s); err == nil {
+		t.Error("unmarshaling a bool succeeded")
+	}
+}
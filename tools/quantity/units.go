@@ -0,0 +1,187 @@
+package quantity
+
+// ByteSize is an amount of memory or storage as a number of bytes.
+type ByteSize int64
+
+// Common byte sizes. The decimal units count in powers of 1000 and the
+// binary units in powers of 1024.
+const (
+	Byte ByteSize = 1
+
+	KB = 1000 * Byte
+	MB = 1000 * KB
+	GB = 1000 * MB
+	TB = 1000 * GB
+	PB = 1000 * TB
+
+	KiB = 1024 * Byte
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+	PiB = 1024 * TiB
+)
+
+// ByteSizes formats byte sizes in the binary units and parses both.
+var ByteSizes = System[ByteSize]{
+	Units: []Unit[ByteSize]{
+		{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB}, {"B", Byte},
+	},
+	Aliases: []Unit[ByteSize]{
+		{"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB}, {"kB", KB},
+	},
+}
+
+// ParseByteSize parses a byte size like "1.5GiB" or "512MB".
+func ParseByteSize(s string) (ByteSize, error) {
+	return ByteSizes.Parse(s)
+}
+
+// String returns the size in the largest binary unit it reaches.
+func (b ByteSize) String() string {
+	return ByteSizes.Format(b)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The text
+// is exact, so it unmarshals to the same value.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(ByteSizes.FormatExact(b)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ByteSizes.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a
+// string or a number of bytes.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	v, err := ByteSizes.ParseJSON(data)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// =============================================================================
+
+// Length is a distance as a number of micrometres.
+type Length int64
+
+// Common lengths.
+const (
+	Micrometre Length = 1
+	Millimetre        = 1000 * Micrometre
+	Centimetre        = 10 * Millimetre
+	Metre             = 100 * Centimetre
+	Kilometre         = 1000 * Metre
+)
+
+// Lengths formats and parses lengths in metric units.
+var Lengths = System[Length]{
+	Units: []Unit[Length]{
+		{"km", Kilometre}, {"m", Metre}, {"cm", Centimetre}, {"mm", Millimetre}, {"µm", Micrometre},
+	},
+	Aliases: []Unit[Length]{
+		{"um", Micrometre},
+	},
+}
+
+// ParseLength parses a length like "1.5km" or "1m 20cm".
+func ParseLength(s string) (Length, error) {
+	return Lengths.Parse(s)
+}
+
+// String returns the length in the largest unit it reaches.
+func (l Length) String() string {
+	return Lengths.Format(l)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The text
+// is exact, so it unmarshals to the same value.
+func (l Length) MarshalText() ([]byte, error) {
+	return []byte(Lengths.FormatExact(l)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (l *Length) UnmarshalText(text []byte) error {
+	v, err := Lengths.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a
+// string or a number of micrometres.
+func (l *Length) UnmarshalJSON(data []byte) error {
+	v, err := Lengths.ParseJSON(data)
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// =============================================================================
+
+// Mass is a weight as a number of milligrams.
+type Mass int64
+
+// Common masses.
+const (
+	Milligram Mass = 1
+	Gram           = 1000 * Milligram
+	Kilogram       = 1000 * Gram
+	Tonne          = 1000 * Kilogram
+)
+
+// Masses formats and parses masses in metric units.
+var Masses = System[Mass]{
+	Units: []Unit[Mass]{
+		{"t", Tonne}, {"kg", Kilogram}, {"g", Gram}, {"mg", Milligram},
+	},
+}
+
+// ParseMass parses a mass like "1.2kg" or "3kg 200g".
+func ParseMass(s string) (Mass, error) {
+	return Masses.Parse(s)
+}
+
+// String returns the mass in the largest unit it reaches.
+func (m Mass) String() string {
+	return Masses.Format(m)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The text
+// is exact, so it unmarshals to the same value.
+func (m Mass) MarshalText() ([]byte, error) {
+	return []byte(Masses.FormatExact(m)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (m *Mass) UnmarshalText(text []byte) error {
+	v, err := Masses.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a
+// string or a number of milligrams.
+func (m *Mass) UnmarshalJSON(data []byte) error {
+	v, err := Masses.ParseJSON(data)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
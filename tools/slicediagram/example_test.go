@@ -0,0 +1,101 @@
+package slicediagram_test
+
+import (
+	"os"
+
+	"ultimate-go-programming/tools/slicediagram"
+)
+
+// Draw the slices of a program after every step. Write the sequence with
+// WriteSVG instead of WriteText to get the same frames as an image.
+func ExampleSequence() {
+	var seq slicediagram.Sequence
+
+	// Create a slice with a length of 5 elements and a capacity of 8.
+	slice1 := make([]string, 5, 8)
+	slice1[0] = "Apple"
+	slice1[1] = "Orange"
+	slice1[2] = "Banana"
+	slice1[3] = "Grape"
+	slice1[4] = "Plum"
+	seq.Step("slice1 := make([]string, 5, 8)",
+		slicediagram.Slice{Name: "slice1", Value: slice1})
+
+	// slice2 gets its own header but points into the array of slice1.
+	slice2 := slice1[2:4]
+	seq.Step("slice2 := slice1[2:4]",
+		slicediagram.Slice{Name: "slice1", Value: slice1},
+		slicediagram.Slice{Name: "slice2", Value: slice2})
+
+	// A change through slice2 is a change to the array of slice1.
+	slice2[0] = "CHANGED"
+	seq.Step(`slice2[0] = "CHANGED"`,
+		slicediagram.Slice{Name: "slice1", Value: slice1},
+		slicediagram.Slice{Name: "slice2", Value: slice2})
+
+	// Appending to slice2 fits in its capacity, so it overwrites the
+	// element at index 4 of slice1.
+	slice2 = append(slice2, "Kiwi")
+	seq.Step(`slice2 = append(slice2, "Kiwi")`,
+		slicediagram.Slice{Name: "slice1", Value: slice1},
+		slicediagram.Slice{Name: "slice2", Value: slice2})
+
+	// The third index limits the capacity to the length, so the next
+	// append has to copy the element into a new array.
+	takeOneCapOne := slice1[2:3:3]
+	seq.Step("takeOneCapOne := slice1[2:3:3]",
+		slicediagram.Slice{Name: "slice1", Value: slice1},
+		slicediagram.Slice{Name: "takeOneCapOne", Value: takeOneCapOne})
+
+	takeOneCapOne = append(takeOneCapOne, "Kiwi")
+	seq.Step(`takeOneCapOne = append(takeOneCapOne, "Kiwi")`,
+		slicediagram.Slice{Name: "slice1", Value: slice1},
+		slicediagram.Slice{Name: "takeOneCapOne", Value: takeOneCapOne})
+
+	seq.WriteText(os.Stdout)
+
+	// Output:
+	// slice1 := make([]string, 5, 8)
+	//
+	//                0       1        2        3       4      5    6    7
+	// A [8]string  | Apple | Orange | Banana | Grape | Plum |....|....|....|
+	// slice1       [=========================================--------------]  len 5 cap 8
+	//
+	// slice2 := slice1[2:4]
+	//
+	//                0       1        2        3       4      5    6    7
+	// A [8]string  | Apple | Orange | Banana | Grape | Plum |....|....|....|
+	// slice1       [=========================================--------------]  len 5 cap 8
+	// slice2                        [=================---------------------]  len 2 cap 6
+	//
+	// slice2[0] = "CHANGED"
+	//
+	//                0       1        2         3       4      5    6    7
+	// A [8]string  | Apple | Orange | CHANGED | Grape | Plum |....|....|....|
+	// slice1       [==========================================--------------]  len 5 cap 8
+	// slice2                        [==================---------------------]  len 2 cap 6
+	//
+	// slice2 = append(slice2, "Kiwi")
+	//
+	//                0       1        2         3       4      5    6    7
+	// A [8]string  | Apple | Orange | CHANGED | Grape | Kiwi |....|....|....|
+	// slice1       [==========================================--------------]  len 5 cap 8
+	// slice2                        [=========================--------------]  len 3 cap 6
+	//
+	// takeOneCapOne := slice1[2:3:3]
+	//
+	//                  0       1        2         3       4      5    6    7
+	// A [8]string    | Apple | Orange | CHANGED | Grape | Kiwi |....|....|....|
+	// slice1         [==========================================--------------]  len 5 cap 8
+	// takeOneCapOne                   [=========]  len 1 cap 1
+	//
+	// takeOneCapOne = append(takeOneCapOne, "Kiwi")
+	//
+	//                  0       1        2         3       4      5    6    7
+	// A [8]string    | Apple | Orange | CHANGED | Grape | Kiwi |....|....|....|
+	// slice1         [==========================================--------------]  len 5 cap 8
+	//
+	//                  0         1
+	// B [2]string    | CHANGED | Kiwi |
+	// takeOneCapOne  [================]  len 2 cap 2
+}
@@ -0,0 +1,359 @@
+// Package slicediagram draws slice headers and the backing arrays they point
+// into, the box diagrams used to explain slicing and append.
+//
+// A Frame is a snapshot of a set of named slices. Slices whose capacity
+// windows overlap share a backing array and are drawn under the same array,
+// with a bar showing the elements each one reaches through its length (=)
+// and through its capacity (-). Elements no slice reaches through its length
+// are shaded as spare capacity:
+//
+//	               0       1        2        3       4      5    6    7
+//	A [8]string  | Apple | Orange | Banana | Grape | Plum |....|....|....|
+//	slice1       [=========================================--------------]  len 5 cap 8
+//	slice2                        [=================---------------------]  len 2 cap 6
+//
+// A Sequence records a frame after every step of a program so the frames
+// read like an animation, and an array keeps its name from frame to frame.
+// Frames are written as text or SVG.
+package slicediagram
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ultimate-go-programming/tools/table"
+)
+
+// maxCell is the widest an element is drawn, in terminal cells.
+const maxCell = 12
+
+// Slice names a slice to draw. Value must be a slice of any type.
+type Slice struct {
+	Name  string
+	Value any
+}
+
+// Array is a backing array, or the part of it reachable from the slices.
+type Array struct {
+	Name  string
+	Type  string   // Type of the elements.
+	Elems []string // Every element, formatted with the %v verb.
+	Used  []bool   // Whether an element is within the length of a slice.
+}
+
+// Header is the header of a slice and where it points.
+type Header struct {
+	Name   string
+	Array  string // Name of the array the slice points into, empty when there is no array to draw.
+	Offset int    // Index in the array of the first element of the slice.
+	Len    int
+	Cap    int
+	Nil    bool
+}
+
+// Frame is a snapshot of the slices and their backing arrays.
+type Frame struct {
+	Caption string
+	Arrays  []Array
+	Headers []Header
+}
+
+// Sequence is a set of frames recorded one step at a time.
+type Sequence struct {
+	Frames []Frame
+	names  namer
+}
+
+// Capture takes a snapshot of the slices. It panics if a value is not a
+// slice.
+func Capture(caption string, slices ...Slice) Frame {
+	var n namer
+	return capture(caption, &n, slices)
+}
+
+// Step records a frame of the slices. An array gets the name it had in the
+// earlier frames.
+func (s *Sequence) Step(caption string, slices ...Slice) *Frame {
+	s.Frames = append(s.Frames, capture(caption, &s.names, slices))
+	return &s.Frames[len(s.Frames)-1]
+}
+
+// String returns the frames as text.
+func (s *Sequence) String() string {
+	var b strings.Builder
+	s.WriteText(&b)
+	return b.String()
+}
+
+// WriteText writes every frame as text separated by a blank line.
+func (s *Sequence) WriteText(w io.Writer) error {
+	for i, f := range s.Frames {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if err := f.WriteText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+
+// namer names the arrays of a sequence. An array is identified by the range
+// of memory the slices reach, so an array found again in a later frame keeps
+// its name as long as it wasn't freed and reused in between.
+type namer struct {
+	ranges []span
+}
+
+// span is a named range of memory.
+type span struct {
+	start uintptr
+	end   uintptr
+	name  string
+}
+
+// name returns the name of the array in the range.
+func (n *namer) name(start uintptr, end uintptr) string {
+	for i, s := range n.ranges {
+		if start < s.end && s.start < end {
+			n.ranges[i].start = min(s.start, start)
+			n.ranges[i].end = max(s.end, end)
+			return s.name
+		}
+	}
+
+	name := arrayName(len(n.ranges))
+	n.ranges = append(n.ranges, span{start, end, name})
+	return name
+}
+
+// arrayName returns A to Z, then AA, AB and so on.
+func arrayName(i int) string {
+	name := string(rune('A' + i%26))
+	for i = i/26 - 1; i >= 0; i = i/26 - 1 {
+		name = string(rune('A'+i%26)) + name
+	}
+	return name
+}
+
+// window is the memory a slice reaches through its capacity.
+type window struct {
+	header int
+	start  uintptr
+	end    uintptr
+	elem   reflect.Type
+	full   reflect.Value // The slice resliced to its capacity.
+}
+
+// capture takes a snapshot of the slices naming the arrays with n.
+func capture(caption string, n *namer, slices []Slice) Frame {
+	f := Frame{Caption: caption}
+
+	var windows []window
+	for _, s := range slices {
+		v := reflect.ValueOf(s.Value)
+		if v.Kind() != reflect.Slice {
+			panic(fmt.Sprintf("slicediagram: %s is a %T, not a slice", s.Name, s.Value))
+		}
+
+		h := Header{Name: s.Name, Len: v.Len(), Cap: v.Cap(), Nil: v.IsNil()}
+		f.Headers = append(f.Headers, h)
+
+		size := v.Type().Elem().Size()
+		if v.Cap() == 0 || size == 0 {
+			continue
+		}
+		windows = append(windows, window{
+			header: len(f.Headers) - 1,
+			start:  v.Pointer(),
+			end:    v.Pointer() + uintptr(v.Cap())*size,
+			elem:   v.Type().Elem(),
+			full:   v.Slice(0, v.Cap()),
+		})
+	}
+
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].start < windows[j].start
+	})
+
+	// Windows that overlap are slices of the same backing array.
+	for i := 0; i < len(windows); {
+		start, end := windows[i].start, windows[i].end
+		j := i + 1
+		for j < len(windows) && windows[j].start < end && windows[j].elem == windows[i].elem {
+			end = max(end, windows[j].end)
+			j++
+		}
+
+		size := windows[i].elem.Size()
+		a := Array{
+			Name:  n.name(start, end),
+			Type:  windows[i].elem.String(),
+			Elems: make([]string, (end-start)/size),
+			Used:  make([]bool, (end-start)/size),
+		}
+
+		for _, w := range windows[i:j] {
+			off := int((w.start - start) / size)
+			for k := range w.full.Len() {
+				a.Elems[off+k] = fmt.Sprint(w.full.Index(k))
+			}
+
+			h := &f.Headers[w.header]
+			for k := range h.Len {
+				a.Used[off+k] = true
+			}
+			h.Array = a.Name
+			h.Offset = off
+		}
+
+		f.Arrays = append(f.Arrays, a)
+		i = j
+	}
+
+	// Arrays are drawn in the order they were named, not the order they
+	// happen to be in memory.
+	sort.Slice(f.Arrays, func(i, j int) bool {
+		a, b := f.Arrays[i].Name, f.Arrays[j].Name
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+
+	return f
+}
+
+// =============================================================================
+
+// String returns the frame as text.
+func (f Frame) String() string {
+	var b strings.Builder
+	f.WriteText(&b)
+	return b.String()
+}
+
+// WriteText writes the frame as text: the caption, then every array with a
+// line for every slice pointing into it, then the slices without an array.
+func (f Frame) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	if f.Caption != "" {
+		fmt.Fprintf(&b, "%s\n\n", f.Caption)
+	}
+
+	// The labels on the left all get the same width.
+	indent := 0
+	for _, a := range f.Arrays {
+		indent = max(indent, table.StringWidth(arrayLabel(a)))
+	}
+	for _, h := range f.Headers {
+		indent = max(indent, table.StringWidth(h.Name))
+	}
+	indent += 2
+
+	for _, a := range f.Arrays {
+		widths := cellWidths(a)
+
+		var index, cells strings.Builder
+		for i, e := range a.Elems {
+			num := strconv.Itoa(i)
+			index.WriteString("  " + num + strings.Repeat(" ", widths[i]-1-len(num)))
+
+			fill := " "
+			if !a.Used[i] {
+				fill = "."
+			}
+			cells.WriteString("|" + fill + padWith(table.Truncate(e, maxCell, "…"), widths[i]-2, fill) + fill)
+		}
+		cells.WriteString("|")
+
+		b.WriteString(strings.TrimRight(strings.Repeat(" ", indent)+index.String(), " ") + "\n")
+		b.WriteString(padWith(arrayLabel(a), indent, " ") + cells.String() + "\n")
+
+		for _, h := range f.Headers {
+			if h.Array == a.Name {
+				b.WriteString(padWith(h.Name, indent, " ") + bar(h, widths) + fmt.Sprintf("  len %d cap %d\n", h.Len, h.Cap))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	for _, h := range f.Headers {
+		if h.Array != "" {
+			continue
+		}
+		state := "empty"
+		switch {
+		case h.Nil:
+			state = "nil"
+		case h.Cap > 0:
+			// The elements take no memory, so there is no array to draw.
+			state = "zero size"
+		}
+		fmt.Fprintf(&b, "%s%s  len %d cap %d\n", padWith(h.Name, indent, " "), state, h.Len, h.Cap)
+	}
+
+	_, err := io.WriteString(w, strings.TrimRight(b.String(), "\n")+"\n")
+	return err
+}
+
+// arrayLabel returns the name and type of the array.
+func arrayLabel(a Array) string {
+	return fmt.Sprintf("%s [%d]%s", a.Name, len(a.Elems), a.Type)
+}
+
+// cellWidths returns the width of every cell of the array, including one
+// space of padding on each side but not the borders.
+func cellWidths(a Array) []int {
+	widths := make([]int, len(a.Elems))
+	for i, e := range a.Elems {
+		widths[i] = max(table.StringWidth(table.Truncate(e, maxCell, "…")), len(strconv.Itoa(i)), 2) + 2
+	}
+	return widths
+}
+
+// bar draws the elements the slice reaches under the cells of the array:
+// = for the elements within its length and - for the rest of its capacity.
+func bar(h Header, widths []int) string {
+	var b strings.Builder
+	for i, width := range widths[:h.Offset+h.Cap] {
+		if i < h.Offset {
+			b.WriteString(strings.Repeat(" ", width+1))
+			continue
+		}
+
+		fill := "-"
+		if i < h.Offset+h.Len {
+			fill = "="
+		}
+
+		// A border keeps the fill of the element before it.
+		border := "-"
+		switch {
+		case i == h.Offset:
+			border = "["
+		case i <= h.Offset+h.Len:
+			border = "="
+		}
+		b.WriteString(border + strings.Repeat(fill, width))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// padWith pads the string on the right to the width with the fill.
+func padWith(s string, width int, fill string) string {
+	if n := width - table.StringWidth(s); n > 0 {
+		return s + strings.Repeat(fill, n)
+	}
+	return s
+}
@@ -0,0 +1,206 @@
+package slicediagram
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestArrayName(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{675, "YZ"},
+		{676, "ZA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := arrayName(tt.i); got != tt.want {
+			t.Errorf("arrayName(%d) = %s, want %s", tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestCapture(t *testing.T) {
+	backing := []int{0, 1, 2, 3, 4, 5}
+	other := []int{6, 7}
+	var none []int
+
+	f := Capture("caption",
+		Slice{"tail", backing[4:5]},
+		Slice{"head", backing[:2:3]},
+		Slice{"other", other},
+		Slice{"nil", none},
+		Slice{"empty", backing[6:]},
+		Slice{"zero", make([]struct{}, 2)},
+	)
+
+	// The arrays are named in memory order, so describe every slice by
+	// the array it points into rather than by its name.
+	arrays := make(map[string]string)
+	for _, a := range f.Arrays {
+		arrays[a.Name] = fmt.Sprintf("[%d]%s %v %v", len(a.Elems), a.Type, a.Elems, a.Used)
+	}
+	if len(arrays) != 3 {
+		t.Errorf("got %d arrays, want 3", len(arrays))
+	}
+
+	got := make([]string, 0, len(f.Headers))
+	for _, h := range f.Headers {
+		got = append(got, fmt.Sprintf("%s %q %d %d %d %v", h.Name, arrays[h.Array], h.Offset, h.Len, h.Cap, h.Nil))
+	}
+
+	// The windows of head and tail don't overlap, so each gets an array.
+	want := []string{
+		`tail "[2]int [4 5] [true false]" 0 1 2 false`,
+		`head "[3]int [0 1 2] [true true false]" 0 2 3 false`,
+		`other "[2]int [6 7] [true true]" 0 2 2 false`,
+		`nil "" 0 0 0 true`,
+		`empty "" 0 0 0 false`,
+		`zero "" 0 2 2 false`,
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCaptureShared(t *testing.T) {
+	backing := []string{"a", "b", "c", "d", "e"}
+
+	f := Capture("",
+		Slice{"mid", backing[1:3]},
+		Slice{"all", backing},
+		Slice{"end", backing[3:]},
+	)
+
+	if len(f.Arrays) != 1 {
+		t.Fatalf("got %d arrays, want 1", len(f.Arrays))
+	}
+	a := f.Arrays[0]
+	if got := fmt.Sprint(a.Elems); got != "[a b c d e]" {
+		t.Errorf("Elems = %s", got)
+	}
+
+	offsets := map[string]int{"mid": 1, "all": 0, "end": 3}
+	for _, h := range f.Headers {
+		if h.Array != a.Name || h.Offset != offsets[h.Name] {
+			t.Errorf("%s points to %s at %d, want %s at %d", h.Name, h.Array, h.Offset, a.Name, offsets[h.Name])
+		}
+	}
+}
+
+func TestCapturePanics(t *testing.T) {
+	defer func() {
+		r := recover()
+		if want := "slicediagram: array is a [2]int, not a slice"; r != want {
+			t.Errorf("got panic %v, want %q", r, want)
+		}
+	}()
+	Capture("", Slice{"array", [2]int{}})
+}
+
+func TestSequenceNames(t *testing.T) {
+	var seq Sequence
+
+	s := make([]int, 1, 2)
+	t1 := make([]int, 1)
+	seq.Step("", Slice{"s", s})
+	seq.Step("", Slice{"t", t1}, Slice{"s", s})
+
+	// The append fits, so s keeps its array.
+	s = append(s, 1)
+	seq.Step("", Slice{"s", s}, Slice{"t", t1})
+
+	// The append doesn't fit, so s gets a new array.
+	s = append(s, 2)
+	seq.Step("", Slice{"s", s}, Slice{"t", t1})
+
+	var got []string
+	for _, f := range seq.Frames {
+		var names []string
+		for _, h := range f.Headers {
+			names = append(names, h.Name+"="+h.Array)
+		}
+		got = append(got, strings.Join(names, " "))
+	}
+
+	want := []string{"s=A", "t=B s=A", "s=A t=B", "s=C t=B"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	words := []string{"a<b", "你好世界", "a very long element", ""}
+	var none []int
+
+	tests := []struct {
+		name  string
+		frame Frame
+		want  string
+	}{
+		{
+			name: "wide and long elements",
+			frame: Capture("",
+				Slice{"words", words[1:3]},
+			),
+			want: `               0          1              2
+A [3]string  | 你好世界 | a very long… |....|
+words        [==========================----]  len 2 cap 3
+`,
+		},
+		{
+			name: "no array",
+			frame: Capture("no array",
+				Slice{"nil", none},
+				Slice{"empty", []int{}},
+				Slice{"zero", make([]struct{}, 3)},
+			),
+			want: `no array
+
+nil    nil  len 0 cap 0
+empty  empty  len 0 cap 0
+zero   zero size  len 3 cap 3
+`,
+		},
+		{
+			name: "spare capacity",
+			frame: Capture("",
+				Slice{"s", make([]bool, 11, 12)[9:]},
+			),
+			want: `             0       1       2
+A [3]bool  | false | false |.false.|
+s          [================-------]  len 2 cap 3
+`,
+		},
+		{
+			name: "two digit indexes",
+			frame: Capture("",
+				Slice{"s", make([]byte, 11)},
+			),
+			want: `               0    1    2    3    4    5    6    7    8    9    10
+A [11]uint8  | 0  | 0  | 0  | 0  | 0  | 0  | 0  | 0  | 0  | 0  | 0  |
+s            [======================================================]  len 11 cap 11
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.frame.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package slicediagram
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"ultimate-go-programming/tools/table"
+)
+
+// Layout of the SVG drawing in pixels.
+const (
+	charWidth  = 8  // Width of a character of the monospace font.
+	margin     = 16 // Space around the drawing and between the parts.
+	cellHeight = 28 // Height of an element and of a slice header.
+	fieldWidth = 56 // Width of each of the three fields of a slice header.
+	rowHeight  = 44 // Space taken by every slice under an array.
+)
+
+// WriteSVG writes the frame as an SVG image.
+func (f Frame) WriteSVG(w io.Writer) error {
+	return writeSVG(w, []Frame{f})
+}
+
+// WriteSVG writes every frame as an SVG image, one under the other.
+func (s *Sequence) WriteSVG(w io.Writer) error {
+	return writeSVG(w, s.Frames)
+}
+
+// writeSVG draws the frames one under the other. Every slice header is
+// drawn as its three fields with an arrow from the pointer to the element
+// it points to and a bar under the elements it reaches.
+func writeSVG(w io.Writer, frames []Frame) error {
+	var body strings.Builder
+	width, y := 0, margin
+
+	for _, f := range frames {
+		fw, fh := drawFrame(&body, f, y)
+		width = max(width, fw)
+		y += fh + margin
+	}
+
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="13">
+<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z"/></marker></defs>
+<rect width="100%%" height="100%%" fill="white"/>
+%s</svg>
+`, width+margin, y, body.String())
+	return err
+}
+
+// drawFrame draws the frame with its top at y and returns the width and
+// height it takes.
+func drawFrame(b *strings.Builder, f Frame, y int) (int, int) {
+	top := y
+	width := 0
+
+	if f.Caption != "" {
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" font-weight=\"bold\">%s</text>\n", margin, y+14, html.EscapeString(f.Caption))
+		y += 14 + margin
+		width = max(width, margin+len(f.Caption)*charWidth)
+	}
+
+	// The arrays start right of the slice headers and their names.
+	nameWidth := 0
+	for _, h := range f.Headers {
+		nameWidth = max(nameWidth, table.StringWidth(h.Name)*charWidth)
+	}
+	left := margin + nameWidth + 8 + 3*fieldWidth + 2*margin
+
+	for _, a := range f.Arrays {
+		widths := cellWidths(a)
+		xs := make([]int, len(widths)+1)
+		xs[0] = left
+		for i, cw := range widths {
+			xs[i+1] = xs[i] + cw*charWidth
+		}
+
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\">%s</text>\n", margin, y+14+cellHeight/2, html.EscapeString(arrayLabel(a)))
+		for i, e := range a.Elems {
+			fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" font-size=\"10\" fill=\"#888888\" text-anchor=\"middle\">%d</text>\n",
+				(xs[i]+xs[i+1])/2, y+10, i)
+
+			fill := "#ffffff"
+			if !a.Used[i] {
+				fill = "#dddddd"
+			}
+			fmt.Fprintf(b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\" stroke=\"black\"/>\n",
+				xs[i], y+14, xs[i+1]-xs[i], cellHeight, fill)
+			fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+				(xs[i]+xs[i+1])/2, y+14+cellHeight/2+5, html.EscapeString(table.Truncate(e, maxCell, "…")))
+		}
+		width = max(width, xs[len(xs)-1])
+
+		bottom := y + 14 + cellHeight
+		y = bottom + margin
+
+		for _, h := range f.Headers {
+			if h.Array != a.Name {
+				continue
+			}
+			drawHeader(b, h, margin, nameWidth, y)
+
+			// The pointer runs under the header and up to its element.
+			px := margin + nameWidth + 8 + fieldWidth/2
+			ex := (xs[h.Offset] + xs[h.Offset+1]) / 2
+			under := y + cellHeight + 6
+			fmt.Fprintf(b, "<circle cx=\"%d\" cy=\"%d\" r=\"3\"/>\n", px, y+cellHeight/2)
+			fmt.Fprintf(b, "<path d=\"M%d,%d V%d H%d V%d\" fill=\"none\" stroke=\"black\" marker-end=\"url(#arrow)\"/>\n",
+				px, y+cellHeight/2, under, ex, bottom+2)
+
+			// The bar shows the length solid and the capacity dashed.
+			by := y + cellHeight/2
+			fmt.Fprintf(b, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"black\" stroke-width=\"4\"/>\n",
+				xs[h.Offset]+2, by, xs[h.Offset+h.Len]-2, by)
+			if h.Cap > h.Len {
+				fmt.Fprintf(b, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#888888\" stroke-width=\"2\" stroke-dasharray=\"4 3\"/>\n",
+					xs[h.Offset+h.Len]+2, by, xs[h.Offset+h.Cap]-2, by)
+			}
+
+			y += rowHeight
+		}
+		y += margin
+	}
+
+	// Slices without an array have nothing to point to.
+	for _, h := range f.Headers {
+		if h.Array != "" {
+			continue
+		}
+		drawHeader(b, h, margin, nameWidth, y)
+		y += rowHeight
+	}
+
+	return width, y - top
+}
+
+// drawHeader draws the name of the slice and its three fields.
+func drawHeader(b *strings.Builder, h Header, x int, nameWidth int, y int) {
+	fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\">%s</text>\n", x, y+cellHeight/2+5, html.EscapeString(h.Name))
+
+	ptr := "ptr"
+	if h.Nil {
+		ptr = "nil"
+	}
+	fields := []string{ptr, "len " + strconv.Itoa(h.Len), "cap " + strconv.Itoa(h.Cap)}
+
+	x += nameWidth + 8
+	for i, field := range fields {
+		fmt.Fprintf(b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"#eeeeee\" stroke=\"black\"/>\n",
+			x+i*fieldWidth, y, fieldWidth, cellHeight)
+		if i == 0 && h.Array != "" {
+			continue
+		}
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+			x+i*fieldWidth+fieldWidth/2, y+cellHeight/2+5, field)
+	}
+}
@@ -0,0 +1,97 @@
+package slicediagram
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteSVG(t *testing.T) {
+	var none []int
+	f := Capture("s = s[:1]",
+		Slice{"s", []int{7, 8}[:1:2]},
+		Slice{"nil", none},
+	)
+
+	var b strings.Builder
+	if err := f.WriteSVG(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<svg xmlns="http://www.w3.org/2000/svg" width="328" height="224" font-family="monospace" font-size="13">
+<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z"/></marker></defs>
+<rect width="100%" height="100%" fill="white"/>
+<text x="16" y="30" font-weight="bold">s = s[:1]</text>
+<text x="16" y="74">A [2]int</text>
+<text x="264" y="56" font-size="10" fill="#888888" text-anchor="middle">0</text>
+<rect x="248" y="60" width="32" height="28" fill="#ffffff" stroke="black"/>
+<text x="264" y="79" text-anchor="middle">7</text>
+<text x="296" y="56" font-size="10" fill="#888888" text-anchor="middle">1</text>
+<rect x="280" y="60" width="32" height="28" fill="#dddddd" stroke="black"/>
+<text x="296" y="79" text-anchor="middle">8</text>
+<text x="16" y="123">s</text>
+<rect x="48" y="104" width="56" height="28" fill="#eeeeee" stroke="black"/>
+<rect x="104" y="104" width="56" height="28" fill="#eeeeee" stroke="black"/>
+<text x="132" y="123" text-anchor="middle">len 1</text>
+<rect x="160" y="104" width="56" height="28" fill="#eeeeee" stroke="black"/>
+<text x="188" y="123" text-anchor="middle">cap 2</text>
+<circle cx="76" cy="118" r="3"/>
+<path d="M76,118 V138 H264 V90" fill="none" stroke="black" marker-end="url(#arrow)"/>
+<line x1="250" y1="118" x2="278" y2="118" stroke="black" stroke-width="4"/>
+<line x1="282" y1="118" x2="310" y2="118" stroke="#888888" stroke-width="2" stroke-dasharray="4 3"/>
+<text x="16" y="183">nil</text>
+<rect x="48" y="164" width="56" height="28" fill="#eeeeee" stroke="black"/>
+<text x="76" y="183" text-anchor="middle">nil</text>
+<rect x="104" y="164" width="56" height="28" fill="#eeeeee" stroke="black"/>
+<text x="132" y="183" text-anchor="middle">len 0</text>
+<rect x="160" y="164" width="56" height="28" fill="#eeeeee" stroke="black"/>
+<text x="188" y="183" text-anchor="middle">cap 0</text>
+</svg>
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+// TestWriteSVGText checks that a sequence is valid XML and that the text in
+// it is escaped.
+func TestWriteSVGText(t *testing.T) {
+	var seq Sequence
+	words := []string{"a<b", "x & y", `"q"`}
+	seq.Step(`words := []string{"a<b"}`, Slice{"words", words[:1]})
+	seq.Step("", Slice{"<words>", words}, Slice{"empty", []string{}})
+
+	var b strings.Builder
+	if err := seq.WriteSVG(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	d := xml.NewDecoder(strings.NewReader(b.String()))
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("not valid XML: %v\n%s", err, b.String())
+		}
+		if cd, ok := tok.(xml.CharData); ok && strings.TrimSpace(string(cd)) != "" {
+			texts = append(texts, string(cd))
+		}
+	}
+
+	want := []string{
+		`words := []string{"a<b"}`,
+		"A [3]string", "0", "a<b", "1", "x & y", "2", `"q"`,
+		"words", "len 1", "cap 3",
+		"A [3]string", "0", "a<b", "1", "x & y", "2", `"q"`,
+		"<words>", "len 3", "cap 3",
+		"empty", "ptr", "len 0", "cap 0",
+	}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("got texts %q\nwant %q", texts, want)
+	}
+}
@@ -0,0 +1,322 @@
+// Package table renders rows of text as a table that stays aligned when the
+// cells hold wide characters.
+//
+// Aligning with tabs or with the width verbs of fmt counts bytes or runes,
+// but a terminal draws "世界" two cells per rune and draws a combining
+// accent on top of the previous character. The table measures every cell
+// in terminal cells instead, so "你好世界" lines up with "Hello World".
+// Tables can also be written as CSV or Markdown.
+package table
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Align decides where a cell's text goes when the column is wider.
+type Align int
+
+// Set of alignments.
+const (
+	Left Align = iota
+	Right
+	Center
+)
+
+// Format decides how a table is written.
+type Format int
+
+// Set of formats.
+const (
+	Plain    Format = iota // Columns separated by spaces.
+	Bordered               // Columns and rows framed with ASCII borders.
+	CSV                    // Comma separated values without padding.
+	Markdown               // A GitHub flavored Markdown table.
+)
+
+// Column describes a column of the table.
+type Column struct {
+	Header   string
+	Align    Align
+	MaxWidth int // Cells wider than this are truncated. Zero means no limit.
+}
+
+// Table is a set of rows with the same columns.
+type Table struct {
+	Columns  []Column
+	Ellipsis string // Added to truncated cells. Defaults to "…".
+	rows     [][]string
+}
+
+// New constructs a table with left aligned columns with the specified
+// headers.
+func New(headers ...string) *Table {
+	t := Table{
+		Columns:  make([]Column, len(headers)),
+		Ellipsis: "…",
+	}
+	for i, h := range headers {
+		t.Columns[i].Header = h
+	}
+	return &t
+}
+
+// Align sets the alignment of the columns at the specified indexes.
+func (t *Table) Align(align Align, cols ...int) *Table {
+	for _, c := range cols {
+		t.Columns[c].Align = align
+	}
+	return t
+}
+
+// MaxWidth limits the width of the column at the specified index.
+func (t *Table) MaxWidth(col int, width int) *Table {
+	t.Columns[col].MaxWidth = width
+	return t
+}
+
+// AddRow adds a row with a cell for every value. Values are formatted with
+// the %v verb. Missing cells are left empty and extra values are dropped.
+func (t *Table) AddRow(values ...any) {
+	row := make([]string, len(t.Columns))
+	for i := range min(len(values), len(row)) {
+		row[i] = clean(fmt.Sprint(values[i]))
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Len returns the number of rows.
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// String returns the table in the Plain format.
+func (t *Table) String() string {
+	var b strings.Builder
+	t.Write(&b, Plain)
+	return b.String()
+}
+
+// Write writes the table in the specified format.
+func (t *Table) Write(w io.Writer, format Format) error {
+	switch format {
+	case Plain:
+		return t.writePlain(w)
+	case Bordered:
+		return t.writeBordered(w)
+	case CSV:
+		return t.writeCSV(w)
+	case Markdown:
+		return t.writeMarkdown(w)
+	}
+	return fmt.Errorf("unknown table format %d", format)
+}
+
+// =============================================================================
+
+// writePlain writes the columns separated by two spaces. Lines are trimmed
+// so they don't end in padding.
+func (t *Table) writePlain(w io.Writer) error {
+	widths := t.widths()
+
+	line := func(cells []string) error {
+		var b strings.Builder
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(pad(t.fit(cell, i, widths[i]), widths[i], t.Columns[i].Align))
+		}
+		_, err := fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+		return err
+	}
+
+	if err := line(t.headers()); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if err := line(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBordered writes the table framed with ASCII borders.
+func (t *Table) writeBordered(w io.Writer) error {
+	widths := t.widths()
+
+	var sep strings.Builder
+	sep.WriteByte('+')
+	for _, width := range widths {
+		sep.WriteString(strings.Repeat("-", width+2))
+		sep.WriteByte('+')
+	}
+	border := sep.String()
+
+	line := func(cells []string) error {
+		var b strings.Builder
+		b.WriteByte('|')
+		for i, cell := range cells {
+			b.WriteByte(' ')
+			b.WriteString(pad(t.fit(cell, i, widths[i]), widths[i], t.Columns[i].Align))
+			b.WriteString(" |")
+		}
+		_, err := fmt.Fprintln(w, b.String())
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, border); err != nil {
+		return err
+	}
+	if err := line(t.headers()); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, border); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if err := line(row); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, border)
+	return err
+}
+
+// writeCSV writes the headers and rows as CSV. Cells are never truncated.
+func (t *Table) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.headers()); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeMarkdown writes the table as Markdown with the cells padded so the
+// source lines up as well.
+func (t *Table) writeMarkdown(w io.Writer) error {
+	widths := t.widths()
+
+	// Escaping the pipes makes cells wider so the widths are measured again.
+	escape := func(cells []string) []string {
+		out := make([]string, len(cells))
+		for i, cell := range cells {
+			out[i] = strings.ReplaceAll(t.fit(cell, i, widths[i]), "|", `\|`)
+		}
+		return out
+	}
+
+	headers := escape(t.headers())
+	rows := make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = escape(row)
+	}
+
+	mdWidths := make([]int, len(widths))
+	for i, h := range headers {
+		mdWidths[i] = max(StringWidth(h), 3)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			mdWidths[i] = max(mdWidths[i], StringWidth(cell))
+		}
+	}
+
+	line := func(cells []string) error {
+		var b strings.Builder
+		b.WriteByte('|')
+		for i, cell := range cells {
+			b.WriteByte(' ')
+			b.WriteString(pad(cell, mdWidths[i], t.Columns[i].Align))
+			b.WriteString(" |")
+		}
+		_, err := fmt.Fprintln(w, b.String())
+		return err
+	}
+
+	if err := line(headers); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteByte('|')
+	for i, width := range mdWidths {
+		dashes := strings.Repeat("-", width)
+		switch t.Columns[i].Align {
+		case Right:
+			dashes = dashes[1:] + ":"
+		case Center:
+			dashes = ":" + dashes[2:] + ":"
+		}
+		b.WriteString(" " + dashes + " |")
+	}
+	if _, err := fmt.Fprintln(w, b.String()); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := line(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+
+// headers returns the header of every column.
+func (t *Table) headers() []string {
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+// widths returns the width in terminal cells of every column.
+func (t *Table) widths() []int {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = StringWidth(c.Header)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], StringWidth(cell))
+		}
+	}
+	for i, c := range t.Columns {
+		if c.MaxWidth > 0 {
+			widths[i] = min(widths[i], c.MaxWidth)
+		}
+	}
+	return widths
+}
+
+// fit truncates the cell to the width of its column.
+func (t *Table) fit(cell string, col int, width int) string {
+	if t.Columns[col].MaxWidth == 0 {
+		return cell
+	}
+	return Truncate(cell, width, t.Ellipsis)
+}
+
+// clean replaces the characters that would break a row across lines or
+// make its width unpredictable.
+func clean(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\n', '\r', '\t':
+			return ' '
+		}
+		return r
+	}, s)
+}
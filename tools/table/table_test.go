@@ -0,0 +1,164 @@
+package table
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newTable returns a table mixing narrow and wide cells.
+func newTable() *Table {
+	t := New("name", "greeting", "n")
+	t.Align(Right, 2)
+	t.AddRow("English", "Hello World", 1)
+	t.AddRow("Chinese", "你好世界", 22)
+	t.AddRow("French", "Ça va | bien", 333)
+	return t
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  *Table
+		format Format
+		want   string
+	}{
+		{
+			name:   "plain",
+			table:  newTable(),
+			format: Plain,
+			want: `name     greeting        n
+English  Hello World     1
+Chinese  你好世界       22
+French   Ça va | bien  333
+`,
+		},
+		{
+			name:   "bordered",
+			table:  newTable(),
+			format: Bordered,
+			want: `+---------+--------------+-----+
+| name    | greeting     |   n |
++---------+--------------+-----+
+| English | Hello World  |   1 |
+| Chinese | 你好世界     |  22 |
+| French  | Ça va | bien | 333 |
++---------+--------------+-----+
+`,
+		},
+		{
+			name:   "csv",
+			table:  newTable(),
+			format: CSV,
+			want: `name,greeting,n
+English,Hello World,1
+Chinese,你好世界,22
+French,Ça va | bien,333
+`,
+		},
+		{
+			name:   "markdown",
+			table:  newTable(),
+			format: Markdown,
+			want: `| name    | greeting      |   n |
+| ------- | ------------- | --: |
+| English | Hello World   |   1 |
+| Chinese | 你好世界      |  22 |
+| French  | Ça va \| bien | 333 |
+`,
+		},
+		{
+			name:   "max width",
+			table:  newTable().MaxWidth(1, 6),
+			format: Plain,
+			want: `name     greet…    n
+English  Hello…    1
+Chinese  你好…    22
+French   Ça va…  333
+`,
+		},
+		{
+			name: "center",
+			table: func() *Table {
+				t := New("x", "wide column").Align(Center, 1)
+				t.AddRow("a", "b")
+				t.AddRow("a", "世")
+				return t
+			}(),
+			format: Markdown,
+			want: `| x   | wide column |
+| --- | :---------: |
+| a   |      b      |
+| a   |     世      |
+`,
+		},
+		{
+			name: "short and long rows",
+			table: func() *Table {
+				t := New("a", "b")
+				t.AddRow(1)
+				t.AddRow(1, 2, 3)
+				t.AddRow("multi\nline\ttext", "")
+				return t
+			}(),
+			format: Plain,
+			want: `a                b
+1
+1                2
+multi line text
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tt.table.Write(&b, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+// failWriter fails every write after the first n bytes.
+type failWriter struct {
+	n int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteErrors(t *testing.T) {
+	for _, format := range []Format{Plain, Bordered, CSV, Markdown} {
+		for _, n := range []int{0, 10, 50} {
+			if err := newTable().Write(&failWriter{n: n}, format); !errors.Is(err, errWrite) {
+				t.Errorf("format %d failing after %d bytes: got %v, want %v", format, n, err, errWrite)
+			}
+		}
+	}
+
+	if err := newTable().Write(&strings.Builder{}, Format(9)); err == nil {
+		t.Error("Write took an unknown format")
+	}
+}
+
+func TestLen(t *testing.T) {
+	if n := newTable().Len(); n != 3 {
+		t.Errorf("Len = %d, want 3", n)
+	}
+	if s := New("a").String(); s != "a\n" {
+		t.Errorf("String of an empty table = %q, want %q", s, "a\n")
+	}
+}
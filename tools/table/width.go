@@ -0,0 +1,158 @@
+package table
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wide holds the East Asian Wide and Fullwidth ranges a terminal draws two
+// cells wide. It covers Hangul, CJK ideographs, kana, fullwidth forms and the
+// emoji blocks, which is what shows up in practice.
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115F, Stride: 1}, // Hangul Jamo initial consonants.
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1}, // Watch and hourglass.
+		{Lo: 0x2329, Hi: 0x232A, Stride: 1}, // Angle brackets.
+		{Lo: 0x23E9, Hi: 0x23EC, Stride: 1},
+		{Lo: 0x23F0, Hi: 0x23F0, Stride: 1},
+		{Lo: 0x23F3, Hi: 0x23F3, Stride: 1},
+		{Lo: 0x25FD, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x267F, Hi: 0x267F, Stride: 1},
+		{Lo: 0x2693, Hi: 0x2693, Stride: 1},
+		{Lo: 0x26A1, Hi: 0x26A1, Stride: 1},
+		{Lo: 0x26AA, Hi: 0x26AB, Stride: 1},
+		{Lo: 0x26BD, Hi: 0x26BE, Stride: 1},
+		{Lo: 0x26C4, Hi: 0x26C5, Stride: 1},
+		{Lo: 0x26CE, Hi: 0x26CE, Stride: 1},
+		{Lo: 0x26D4, Hi: 0x26D4, Stride: 1},
+		{Lo: 0x26EA, Hi: 0x26EA, Stride: 1},
+		{Lo: 0x26F2, Hi: 0x26F3, Stride: 1},
+		{Lo: 0x26F5, Hi: 0x26F5, Stride: 1},
+		{Lo: 0x26FA, Hi: 0x26FA, Stride: 1},
+		{Lo: 0x26FD, Hi: 0x26FD, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270A, Hi: 0x270B, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x274C, Hi: 0x274C, Stride: 1},
+		{Lo: 0x274E, Hi: 0x274E, Stride: 1},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27B0, Hi: 0x27B0, Stride: 1},
+		{Lo: 0x27BF, Hi: 0x27BF, Stride: 1},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B50, Stride: 1},
+		{Lo: 0x2B55, Hi: 0x2B55, Stride: 1},
+		{Lo: 0x2E80, Hi: 0x303E, Stride: 1}, // CJK radicals, symbols and punctuation.
+		{Lo: 0x3041, Hi: 0x33FF, Stride: 1}, // Kana, Bopomofo and CJK compatibility.
+		{Lo: 0x3400, Hi: 0x4DBF, Stride: 1}, // CJK Extension A.
+		{Lo: 0x4E00, Hi: 0x9FFF, Stride: 1}, // CJK Unified Ideographs.
+		{Lo: 0xA000, Hi: 0xA4CF, Stride: 1}, // Yi.
+		{Lo: 0xA960, Hi: 0xA97F, Stride: 1}, // Hangul Jamo Extended-A.
+		{Lo: 0xAC00, Hi: 0xD7A3, Stride: 1}, // Hangul syllables.
+		{Lo: 0xF900, Hi: 0xFAFF, Stride: 1}, // CJK compatibility ideographs.
+		{Lo: 0xFE10, Hi: 0xFE19, Stride: 1}, // Vertical forms.
+		{Lo: 0xFE30, Hi: 0xFE6F, Stride: 1}, // CJK compatibility forms.
+		{Lo: 0xFF00, Hi: 0xFF60, Stride: 1}, // Fullwidth forms.
+		{Lo: 0xFFE0, Hi: 0xFFE6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x16FE0, Hi: 0x16FE4, Stride: 1},
+		{Lo: 0x17000, Hi: 0x18CFF, Stride: 1}, // Tangut.
+		{Lo: 0x1B000, Hi: 0x1B2FF, Stride: 1}, // Kana supplement.
+		{Lo: 0x1F004, Hi: 0x1F004, Stride: 1},
+		{Lo: 0x1F0CF, Hi: 0x1F0CF, Stride: 1},
+		{Lo: 0x1F18E, Hi: 0x1F18E, Stride: 1},
+		{Lo: 0x1F191, Hi: 0x1F19A, Stride: 1},
+		{Lo: 0x1F200, Hi: 0x1F2FF, Stride: 1}, // Enclosed ideographic supplement.
+		{Lo: 0x1F300, Hi: 0x1F64F, Stride: 1}, // Pictographs and emoticons.
+		{Lo: 0x1F680, Hi: 0x1F6FF, Stride: 1}, // Transport and map symbols.
+		{Lo: 0x1F7E0, Hi: 0x1F7EB, Stride: 1},
+		{Lo: 0x1F90C, Hi: 0x1F9FF, Stride: 1}, // Supplemental symbols and pictographs.
+		{Lo: 0x1FA70, Hi: 0x1FAFF, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2FFFD, Stride: 1}, // CJK Extensions B to F.
+		{Lo: 0x30000, Hi: 0x3FFFD, Stride: 1}, // CJK Extension G and beyond.
+	},
+}
+
+// zero holds the runes that take no room of their own: combining marks
+// attach to the previous character and format characters are invisible.
+var zero = []*unicode.RangeTable{
+	unicode.Mn,
+	unicode.Me,
+	unicode.Cf,
+}
+
+// RuneWidth returns the number of terminal cells the rune occupies. Control
+// characters, combining marks and zero width characters take no cells, East
+// Asian wide and fullwidth characters take two and everything else takes one.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20, r >= 0x7F && r < 0xA0:
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.In(r, zero...):
+		return 0
+	case r >= 0x1160 && r <= 0x11FF:
+		// Hangul medial vowels and final consonants join the initial.
+		return 0
+	case unicode.Is(wide, r):
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the number of terminal cells the string occupies.
+// Invalid bytes are drawn as the replacement character and take one cell.
+func StringWidth(s string) int {
+	var width int
+	for _, r := range s {
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// Truncate shortens the string so it occupies at most width cells. When the
+// string is cut the tail is added at the end and counts towards the width.
+// Combining marks that follow the last character kept stay with it.
+func Truncate(s string, width int, tail string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+
+	tw := StringWidth(tail)
+	if tw > width {
+		tail, tw = "", 0
+	}
+
+	var used int
+	for i, r := range s {
+		rw := RuneWidth(r)
+		if used+rw > width-tw {
+			return s[:i] + tail
+		}
+		used += rw
+	}
+
+	return s
+}
+
+// pad adds spaces to the string so it occupies width cells with the
+// specified alignment.
+func pad(s string, width int, align Align) string {
+	n := width - StringWidth(s)
+	if n <= 0 {
+		return s
+	}
+
+	switch align {
+	case Right:
+		return strings.Repeat(" ", n) + s
+	case Center:
+		return strings.Repeat(" ", n/2) + s + strings.Repeat(" ", n-n/2)
+	}
+	return s + strings.Repeat(" ", n)
+}
@@ -0,0 +1,108 @@
+package table
+
+import "testing"
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want int
+	}{
+		{'a', 1},
+		{'é', 1},
+		{'\t', 0},
+		{0x7F, 0},
+		{0x85, 0},
+		{'\u0301', 0}, // Combining acute accent.
+		{'\u200b', 0}, // Zero width space.
+		{'\u1161', 0}, // Hangul medial vowel.
+		{'\u1100', 2}, // Hangul initial consonant.
+		{'世', 2},
+		{'한', 2},
+		{'カ', 2},
+		{'Ａ', 2}, // Fullwidth A.
+		{'ｶ', 1}, // Halfwidth katakana.
+		{'😀', 2},
+		{'⌚', 2},
+		{'→', 1},
+		{0x20000, 2},
+	}
+
+	for _, tt := range tests {
+		if got := RuneWidth(tt.r); got != tt.want {
+			t.Errorf("RuneWidth(%U) = %d, want %d", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"Hello World", 11},
+		{"你好世界", 8},
+		{"e\u0301", 1},
+		{"\u00e9", 1},
+		{"a\xffb", 3},
+		{"👍 ok", 5},
+	}
+
+	for _, tt := range tests {
+		if got := StringWidth(tt.s); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		tail  string
+		want  string
+	}{
+		{"Hello", 5, "…", "Hello"},
+		{"Hello World", 5, "…", "Hell…"},
+		{"Hello World", 5, "...", "He..."},
+		{"Hello World", 5, "", "Hello"},
+		{"Hello", 2, "...", "He"},
+		{"你好世界", 5, "…", "你好…"},
+		{"你好世界", 4, "", "你好"},
+		{"你好世界", 3, "", "你"},
+		{"ae\u0301b", 2, "", "ae\u0301"},
+		{"abc", 0, "…", ""},
+	}
+
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.width, tt.tail)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d, %q) = %q, want %q", tt.s, tt.width, tt.tail, got, tt.want)
+		}
+		if StringWidth(got) > tt.width {
+			t.Errorf("Truncate(%q, %d, %q) is %d cells wide", tt.s, tt.width, tt.tail, StringWidth(got))
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		align Align
+		want  string
+	}{
+		{"ab", 5, Left, "ab   "},
+		{"ab", 5, Right, "   ab"},
+		{"ab", 5, Center, " ab  "},
+		{"世界", 6, Left, "世界  "},
+		{"世界", 6, Center, " 世界 "},
+		{"abc", 2, Right, "abc"},
+	}
+
+	for _, tt := range tests {
+		if got := pad(tt.s, tt.width, tt.align); got != tt.want {
+			t.Errorf("pad(%q, %d, %d) = %q, want %q", tt.s, tt.width, tt.align, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package utf8inspect decodes arbitrary bytes as UTF-8 and reports every
+// rune along with its offset and encoding.
+//
+// A string holds arbitrary bytes, so the decoder doesn't stop at the first
+// problem. Bytes that are not valid UTF-8 are reported as a sequence with
+// the reason it is invalid: an unexpected continuation byte, a sequence cut
+// short, an overlong encoding, a surrogate half or a code point beyond
+// U+10FFFF. Combining marks are flagged and grouped with the rune they
+// attach to, which explains why "à" can be one rune (U+00E0) or two (U+0061
+// followed by U+0300) while looking the same.
+package utf8inspect
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ultimate-go-programming/tools/table"
+)
+
+// Kind describes whether a sequence of bytes is a valid encoding.
+type Kind int
+
+// Set of kinds of byte sequences.
+const (
+	Valid      Kind = iota // A valid UTF-8 encoding.
+	Unexpected             // A continuation byte or a byte that never starts a sequence.
+	Incomplete             // A leading byte without all of its continuation bytes.
+	Overlong               // A code point encoded with more bytes than needed.
+	Surrogate              // A UTF-16 surrogate half, U+D800 to U+DFFF.
+	OutOfRange             // A code point greater than U+10FFFF.
+)
+
+// String implements the fmt.Stringer interface.
+func (k Kind) String() string {
+	switch k {
+	case Valid:
+		return "valid"
+	case Unexpected:
+		return "unexpected byte"
+	case Incomplete:
+		return "incomplete sequence"
+	case Overlong:
+		return "overlong encoding"
+	case Surrogate:
+		return "surrogate half"
+	case OutOfRange:
+		return "out of range"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Bytes is an encoded sequence. It is written as space separated hex in
+// JSON instead of base64.
+type Bytes []byte
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (b Bytes) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "% x", []byte(b)), nil
+}
+
+// Rune is a decoded sequence of bytes.
+type Rune struct {
+	Offset    int    `json:"offset"`
+	Bytes     Bytes  `json:"bytes"`
+	Rune      rune   `json:"rune"` // The code point, even when the encoding is invalid.
+	Kind      Kind   `json:"kind"`
+	Category  string `json:"category,omitempty"`
+	Combining bool   `json:"combining,omitempty"`
+}
+
+// Valid reports whether the bytes are a valid UTF-8 encoding.
+func (r Rune) Valid() bool {
+	return r.Kind == Valid
+}
+
+// CodePoint returns the rune in U+XXXX notation. Sequences that don't hold
+// a code point, like a stray continuation byte, return "-".
+func (r Rune) CodePoint() string {
+	if r.Kind == Unexpected || r.Kind == Incomplete {
+		return "-"
+	}
+	return fmt.Sprintf("U+%04X", r.Rune)
+}
+
+// Note explains what is special about the rune, if anything.
+func (r Rune) Note() string {
+	switch {
+	case r.Kind != Valid:
+		return r.Kind.String()
+	case r.Combining:
+		return "combining mark"
+	}
+	return ""
+}
+
+// Inspect decodes every sequence in b.
+func Inspect(b []byte) []Rune {
+	var runes []Rune
+	for offset := 0; offset < len(b); {
+		r := decode(b[offset:])
+		r.Offset = offset
+		r.Bytes = b[offset : offset+len(r.Bytes) : offset+len(r.Bytes)]
+		if r.Kind == Valid {
+			r.Category = category(r.Rune)
+			r.Combining = unicode.Is(unicode.M, r.Rune)
+		}
+
+		runes = append(runes, r)
+		offset += len(r.Bytes)
+	}
+	return runes
+}
+
+// decode decodes the first sequence in b. Only Bytes, Rune and Kind are set.
+func decode(b []byte) Rune {
+	b0 := b[0]
+
+	// Work out the length of the sequence and the bits of the leading byte
+	// that belong to the code point.
+	var size int
+	var cp rune
+	switch {
+	case b0 < 0x80:
+		return Rune{Bytes: b[:1], Rune: rune(b0)}
+	case b0 < 0xC0:
+		return Rune{Bytes: b[:1], Rune: utf8.RuneError, Kind: Unexpected}
+	case b0 < 0xE0:
+		size, cp = 2, rune(b0&0x1F)
+	case b0 < 0xF0:
+		size, cp = 3, rune(b0&0x0F)
+	case b0 < 0xF8:
+		size, cp = 4, rune(b0&0x07)
+	default:
+		return Rune{Bytes: b[:1], Rune: utf8.RuneError, Kind: Unexpected}
+	}
+
+	for i := 1; i < size; i++ {
+		if i == len(b) || b[i]&0xC0 != 0x80 {
+			return Rune{Bytes: b[:i], Rune: utf8.RuneError, Kind: Incomplete}
+		}
+		cp = cp<<6 | rune(b[i]&0x3F)
+	}
+
+	r := Rune{Bytes: b[:size], Rune: cp}
+	switch n := utf8.RuneLen(cp); {
+	case n > 0 && n < size:
+		r.Kind = Overlong
+	case cp > unicode.MaxRune:
+		r.Kind = OutOfRange
+	case 0xD800 <= cp && cp <= 0xDFFF:
+		r.Kind = Surrogate
+	}
+
+	return r
+}
+
+// categoryNames holds the two letter Unicode categories in sorted order so
+// the category of a rune is always reported the same way. LC is left out
+// since it groups Ll, Lt and Lu.
+var categoryNames = func() []string {
+	var names []string
+	for name := range unicode.Categories {
+		if len(name) == 2 && name != "LC" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// category returns the Unicode general category of the rune, like Lu for
+// an upper case letter or Mn for a non spacing mark.
+func category(r rune) string {
+	for _, name := range categoryNames {
+		if unicode.Is(unicode.Categories[name], r) {
+			return name
+		}
+	}
+	return "Cn"
+}
+
+// =============================================================================
+
+// Cluster is a rune followed by the combining marks that attach to it. It
+// is roughly what a reader sees as a single character.
+type Cluster struct {
+	Offset int    `json:"offset"`
+	Runes  []Rune `json:"runes"`
+}
+
+// Bytes returns the bytes of every rune in the cluster.
+func (c Cluster) Bytes() []byte {
+	var b []byte
+	for _, r := range c.Runes {
+		b = append(b, r.Bytes...)
+	}
+	return b
+}
+
+// String returns the code points of the cluster joined with a plus sign.
+func (c Cluster) String() string {
+	cps := make([]string, len(c.Runes))
+	for i, r := range c.Runes {
+		cps[i] = r.CodePoint()
+	}
+	return strings.Join(cps, "+")
+}
+
+// Clusters groups each rune with the combining marks that follow it. A
+// combining mark at the start of the runes or after an invalid sequence
+// starts a cluster of its own.
+func Clusters(runes []Rune) []Cluster {
+	var clusters []Cluster
+	for _, r := range runes {
+		n := len(clusters)
+		if r.Combining && n > 0 && clusters[n-1].Runes[0].Valid() {
+			clusters[n-1].Runes = append(clusters[n-1].Runes, r)
+			continue
+		}
+		clusters = append(clusters, Cluster{Offset: r.Offset, Runes: []Rune{r}})
+	}
+	return clusters
+}
+
+// =============================================================================
+
+// WriteTable writes one line per rune with its offset, encoded bytes, code
+// point, category and anything worth noting.
+func WriteTable(w io.Writer, runes []Rune) error {
+	t := table.New("OFFSET", "BYTES", "CODE POINT", "CHAR", "CATEGORY", "NOTE")
+	t.Align(table.Right, 0)
+
+	for _, r := range runes {
+		char, cat := "-", "-"
+		if r.Valid() {
+			char = display(r)
+			cat = r.Category
+		}
+
+		t.AddRow(r.Offset, fmt.Sprintf("% x", r.Bytes), r.CodePoint(), char, cat, r.Note())
+	}
+
+	return t.Write(w, table.Plain)
+}
+
+// WriteComparison explains two strings cluster by cluster. Clusters that are
+// encoded differently are marked, which is how two visually identical
+// strings end up not being equal.
+func WriteComparison(w io.Writer, a []byte, b []byte) error {
+	ca := Clusters(Inspect(a))
+	cb := Clusters(Inspect(b))
+
+	t := table.New("#", "SAME", "A BYTES", "A CODE POINTS", "B BYTES", "B CODE POINTS")
+	t.Align(table.Right, 0)
+
+	for i := range max(len(ca), len(cb)) {
+		aBytes, aCPs, bBytes, bCPs := "-", "-", "-", "-"
+		if i < len(ca) {
+			aBytes, aCPs = fmt.Sprintf("% x", ca[i].Bytes()), ca[i].String()
+		}
+		if i < len(cb) {
+			bBytes, bCPs = fmt.Sprintf("% x", cb[i].Bytes()), cb[i].String()
+		}
+
+		same := "yes"
+		if aBytes != bBytes {
+			same = "no"
+		}
+
+		t.AddRow(i, same, aBytes, aCPs, bBytes, bCPs)
+	}
+
+	if err := t.Write(w, table.Plain); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "A: %d bytes, %d runes, %d clusters\nB: %d bytes, %d runes, %d clusters\n",
+		len(a), utf8.RuneCount(a), len(ca), len(b), utf8.RuneCount(b), len(cb))
+	return err
+}
+
+// display returns a printable form of the rune. Combining marks are shown
+// on a dotted circle the way character tables do.
+func display(r Rune) string {
+	switch {
+	case r.Combining:
+		return "◌" + string(r.Rune)
+	case unicode.IsGraphic(r.Rune) && !unicode.IsSpace(r.Rune):
+		return string(r.Rune)
+	}
+	return strconv.QuoteRune(r.Rune)
+}
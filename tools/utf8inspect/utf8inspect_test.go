@@ -0,0 +1,202 @@
+package utf8inspect
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string // offset, bytes, code point and note of every rune.
+	}{
+		{
+			name:  "ascii",
+			input: "Go!",
+			want:  []string{"0 47 U+0047", "1 6f U+006F", "2 21 U+0021"},
+		},
+		{
+			name:  "multi byte",
+			input: "\u00e9世😀",
+			want:  []string{"0 c3 a9 U+00E9", "2 e4 b8 96 U+4E16", "5 f0 9f 98 80 U+1F600"},
+		},
+		{
+			name:  "combining",
+			input: "a\u0300",
+			want:  []string{"0 61 U+0061", "1 cc 80 U+0300 combining mark"},
+		},
+		{
+			name:  "continuation byte",
+			input: "a\x80b",
+			want:  []string{"0 61 U+0061", "1 80 - unexpected byte", "2 62 U+0062"},
+		},
+		{
+			name:  "never a leading byte",
+			input: "\xf8\xff",
+			want:  []string{"0 f8 - unexpected byte", "1 ff - unexpected byte"},
+		},
+		{
+			name:  "cut short",
+			input: "\xe4\xb8a",
+			want:  []string{"0 e4 b8 - incomplete sequence", "2 61 U+0061"},
+		},
+		{
+			name:  "cut short at the end",
+			input: "a\xf0\x9f\x98",
+			want:  []string{"0 61 U+0061", "1 f0 9f 98 - incomplete sequence"},
+		},
+		{
+			name:  "overlong",
+			input: "\xc0\xaf\xe0\x80\xaf",
+			want:  []string{"0 c0 af U+002F overlong encoding", "2 e0 80 af U+002F overlong encoding"},
+		},
+		{
+			name:  "surrogate",
+			input: "\xed\xa0\x80",
+			want:  []string{"0 ed a0 80 U+D800 surrogate half"},
+		},
+		{
+			name:  "out of range",
+			input: "\xf4\x90\x80\x80",
+			want:  []string{"0 f4 90 80 80 U+110000 out of range"},
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range Inspect([]byte(tt.input)) {
+				got = append(got, strings.TrimSpace(fmt.Sprintf("%d % x %s %s", r.Offset, r.Bytes, r.CodePoint(), r.Note())))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestInspectRandom checks the decoder against the utf8 package on random
+// bytes: every byte is covered once and every valid rune decodes the same.
+func TestInspectRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// Draw the bytes from the ranges that matter to the decoder.
+	pool := []byte{0x00, 0x41, 0x7f, 0x80, 0x9f, 0xa0, 0xbf, 0xc0, 0xc2, 0xdf, 0xe0, 0xed, 0xef, 0xf0, 0xf4, 0xf5, 0xf8, 0xff}
+
+	for range 5000 {
+		b := make([]byte, rng.Intn(12))
+		for i := range b {
+			b[i] = pool[rng.Intn(len(pool))]
+		}
+
+		var joined []byte
+		for _, r := range Inspect(b) {
+			if r.Offset != len(joined) {
+				t.Fatalf("% x: rune at offset %d, want %d", b, r.Offset, len(joined))
+			}
+			joined = append(joined, r.Bytes...)
+
+			want, size := utf8.DecodeRune(b[r.Offset:])
+			valid := want != utf8.RuneError || size > 1
+			if r.Valid() != valid {
+				t.Fatalf("% x: offset %d is %v, utf8 says valid is %v", b, r.Offset, r.Kind, valid)
+			}
+			if valid && (r.Rune != want || len(r.Bytes) != size) {
+				t.Fatalf("% x: offset %d decodes to %U in %d bytes, want %U in %d", b, r.Offset, r.Rune, len(r.Bytes), want, size)
+			}
+		}
+
+		if !bytes.Equal(joined, b) {
+			t.Fatalf("% x: runes hold % x", b, joined)
+		}
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want string
+	}{
+		{'A', "Lu"},
+		{'a', "Ll"},
+		{'\u01c5', "Lt"},
+		{'7', "Nd"},
+		{' ', "Zs"},
+		{'\u0300', "Mn"},
+		{'€', "Sc"},
+		{'\n', "Cc"},
+		{0x0378, "Cn"},
+	}
+
+	for _, tt := range tests {
+		if got := category(tt.r); got != tt.want {
+			t.Errorf("category(%U) = %s, want %s", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestClusters(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"precomposed", "\u00e0b", []string{"0 U+00E0", "2 U+0062"}},
+		{"decomposed", "a\u0300b", []string{"0 U+0061+U+0300", "3 U+0062"}},
+		{"two marks", "a\u0300\u0301", []string{"0 U+0061+U+0300+U+0301"}},
+		{"leading mark", "\u0300a", []string{"0 U+0300", "2 U+0061"}},
+		{"mark after invalid", "\xff\u0300", []string{"0 -", "1 U+0300"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, c := range Clusters(Inspect([]byte(tt.input))) {
+			got = append(got, fmt.Sprintf("%d %s", c.Offset, c))
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var b strings.Builder
+	if err := WriteTable(&b, Inspect([]byte("e\u0301 \xff"))); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `OFFSET  BYTES  CODE POINT  CHAR  CATEGORY  NOTE
+     0  65     U+0065      e     Ll
+     1  cc 81  U+0301      ◌́     Mn        combining mark
+     3  20     U+0020      ' '   Zs
+     4  ff     -           -     -         unexpected byte
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteComparison(t *testing.T) {
+	var b strings.Builder
+	if err := WriteComparison(&b, []byte("\u00e9!"), []byte("e\u0301!")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `#  SAME  A BYTES  A CODE POINTS  B BYTES   B CODE POINTS
+0  no    c3 a9    U+00E9         65 cc 81  U+0065+U+0301
+1  yes   21       U+0021         21        U+0021
+A: 3 bytes, 2 runes, 2 clusters
+B: 4 bytes, 3 runes, 2 clusters
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
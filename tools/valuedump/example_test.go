@@ -0,0 +1,52 @@
+package valuedump_test
+
+import (
+	"os"
+
+	"ultimate-go-programming/tools/valuedump"
+)
+
+// The dump shows every field along with its type, size and whether it still
+// holds its zero value, even the unexported fields the program can't reach.
+func ExampleFprint() {
+	type toy struct {
+		Weight int
+		onHand int
+		sold   int
+	}
+
+	valuedump.Fprint(os.Stdout, toy{Weight: 11, onHand: 12}, valuedump.Options{})
+
+	// Output:
+	// valuedump_test.toy (struct, 24 bytes)
+	//   Weight int = 11 (int, 8 bytes)
+	//   onHand int = 12 (int, 8 bytes, unexported)
+	//   sold int = 0 (int, 8 bytes, zero, unexported)
+}
+
+// Values with secrets can have their unexported fields, or the fields with
+// some names, redacted.
+func ExampleOptions() {
+	type user struct {
+		ID       int
+		password string
+	}
+
+	type manager struct {
+		Level int
+		user
+	}
+
+	m := manager{Level: 2, user: user{ID: 10, password: "secret"}}
+
+	valuedump.Fprint(os.Stdout, m, valuedump.Options{RedactUnexported: true})
+	valuedump.Fprint(os.Stdout, m.user, valuedump.Options{RedactFields: []string{"Password"}})
+
+	// Output:
+	// valuedump_test.manager (struct, 32 bytes)
+	//   Level int = 2 (int, 8 bytes)
+	//   user valuedump_test.user = <redacted> (struct, 24 bytes, embedded, unexported)
+	// valuedump_test.user (struct, 24 bytes)
+	//   ID int = 10 (int, 8 bytes)
+	//   password string = <redacted> (string, 16 bytes, unexported)
+}
@@ -0,0 +1,441 @@
+// Package valuedump uses reflection to describe any value as a tree: the
+// type of every part of the value, what it holds, whether it is the zero
+// value and how many bytes it takes. Pointers, slices, maps and strings also
+// show the header behind them, like the address a pointer holds or the
+// length, capacity and backing array of a slice.
+//
+// Unexported fields, like the onHand count of toy.Toy or the password of
+// users.User, are read through reflection even though the code that created
+// the value can't reach them. Options can redact them instead. A value reached
+// through more than one pointer, slice or map, including one that holds
+// itself, is only walked once.
+package valuedump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Redacted is the value shown for fields that are redacted.
+const Redacted = "<redacted>"
+
+// Options controls how a value is walked.
+type Options struct {
+	RedactUnexported bool     // Hide the values of unexported fields.
+	RedactFields     []string // Hide the values of fields with these names, ignoring case.
+	MaxDepth         int      // Stop walking below this depth. Zero means no limit.
+	MaxItems         int      // Show at most this many elements of a slice, array or map. Zero means all.
+}
+
+// Node describes a value and, through its children, the values it holds.
+type Node struct {
+	Name       string  `json:"name,omitempty"`       // Field name, index or map key.
+	Type       string  `json:"type"`                 // Static type of the value.
+	Kind       string  `json:"kind"`                 // Kind of the type.
+	Value      string  `json:"value,omitempty"`      // The value of basic types and pointer addresses.
+	Size       uintptr `json:"size"`                 // Bytes taken by the value itself, not what it points to.
+	Zero       bool    `json:"zero"`                 // The value is the zero value of its type.
+	Nil        bool    `json:"nil,omitempty"`        // A pointer, slice, map, func, chan or interface that is nil.
+	Unexported bool    `json:"unexported,omitempty"` // An unexported struct field.
+	Redacted   bool    `json:"redacted,omitempty"`   // The value was hidden.
+	Embedded   bool    `json:"embedded,omitempty"`   // An embedded struct field.
+	Data       string  `json:"data,omitempty"`       // Address of the backing array of a slice or string, or the map header.
+	Len        *int    `json:"len,omitempty"`        // Length of a slice, array, string, map or chan.
+	Cap        *int    `json:"cap,omitempty"`        // Capacity of a slice or chan.
+	Seen       bool    `json:"seen,omitempty"`       // The pointer, slice or map leads to a value already shown, as in a cycle.
+	More       int     `json:"more,omitempty"`       // Elements left out because of MaxItems.
+	Children   []*Node `json:"children,omitempty"`
+}
+
+// Dump walks the value and returns the tree describing it.
+func Dump(v any, opts Options) *Node {
+	d := dumper{
+		opts:    opts,
+		visited: make(map[visit]bool),
+		walked:  make(map[visit]int),
+	}
+
+	if v == nil {
+		return &Node{Type: "nil", Kind: "invalid", Zero: true, Nil: true}
+	}
+	return d.node("", reflect.ValueOf(v), 0)
+}
+
+// Fprint writes the tree describing the value as text.
+func Fprint(w io.Writer, v any, opts Options) error {
+	return Dump(v, opts).WriteText(w)
+}
+
+// WriteText writes the tree with one line per node, indenting children
+// under their parent.
+func (n *Node) WriteText(w io.Writer) error {
+	return n.writeText(w, 0)
+}
+
+// WriteJSON writes the tree as indented JSON.
+func (n *Node) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(n)
+}
+
+// String returns the tree as text.
+func (n *Node) String() string {
+	var b strings.Builder
+	n.WriteText(&b)
+	return b.String()
+}
+
+// writeText writes the node and its children at the indentation level.
+func (n *Node) writeText(w io.Writer, level int) error {
+	var b strings.Builder
+	b.WriteString(strings.Repeat("  ", level))
+	if n.Name != "" {
+		b.WriteString(n.Name + " ")
+	}
+	b.WriteString(n.Type)
+	if n.Value != "" {
+		b.WriteString(" = " + n.Value)
+	}
+
+	var notes []string
+	notes = append(notes, n.Kind, fmt.Sprintf("%d bytes", n.Size))
+	if n.Len != nil {
+		notes = append(notes, fmt.Sprintf("len %d", *n.Len))
+	}
+	if n.Cap != nil {
+		notes = append(notes, fmt.Sprintf("cap %d", *n.Cap))
+	}
+	if n.Data != "" {
+		notes = append(notes, "data "+n.Data)
+	}
+	if n.Zero {
+		notes = append(notes, "zero")
+	}
+	if n.Nil {
+		notes = append(notes, "nil")
+	}
+	if n.Embedded {
+		notes = append(notes, "embedded")
+	}
+	if n.Unexported {
+		notes = append(notes, "unexported")
+	}
+	if n.Seen {
+		notes = append(notes, "shown above")
+	}
+	fmt.Fprintf(&b, " (%s)", strings.Join(notes, ", "))
+
+	if _, err := fmt.Fprintln(w, b.String()); err != nil {
+		return err
+	}
+
+	for _, c := range n.Children {
+		if err := c.writeText(w, level+1); err != nil {
+			return err
+		}
+	}
+
+	if n.More > 0 {
+		if _, err := fmt.Fprintf(w, "%s... %d more\n", strings.Repeat("  ", level+1), n.More); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// visit identifies a value reached through a pointer, or the elements of a
+// slice or map. The type is part of the key since a struct and its first
+// field share an address.
+type visit struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// dumper holds the state of a single walk. Slices record how many elements
+// were walked, since a shorter slice of the same array leaves the rest
+// unseen.
+type dumper struct {
+	opts    Options
+	visited map[visit]bool
+	walked  map[visit]int
+}
+
+// node returns the node for the value and walks what it holds.
+func (d *dumper) node(name string, v reflect.Value, depth int) *Node {
+	n := Node{
+		Name: name,
+		Type: v.Type().String(),
+		Kind: v.Kind().String(),
+		Size: v.Type().Size(),
+		Zero: v.IsZero(),
+	}
+
+	if d.opts.MaxDepth > 0 && depth >= d.opts.MaxDepth {
+		n.Value = "..."
+		return &n
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		n.Value = strconv.FormatBool(v.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n.Value = strconv.FormatInt(v.Int(), 10)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n.Value = strconv.FormatUint(v.Uint(), 10)
+
+	case reflect.Float32, reflect.Float64:
+		n.Value = strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+
+	case reflect.Complex64, reflect.Complex128:
+		n.Value = strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+
+	case reflect.String:
+		n.Value = strconv.Quote(v.String())
+		n.Len = ptr(v.Len())
+		if v.Len() > 0 {
+			n.Data = addr(v.UnsafePointer())
+		}
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			n.Nil = true
+			n.Value = "nil"
+			break
+		}
+		n.Value = addr(v.UnsafePointer())
+
+		key := visit{uintptr(v.UnsafePointer()), v.Type().Elem()}
+		if d.visited[key] {
+			n.Seen = true
+			break
+		}
+		d.visited[key] = true
+		n.Children = append(n.Children, d.node("", v.Elem(), depth+1))
+
+	case reflect.Interface:
+		if v.IsNil() {
+			n.Nil = true
+			n.Value = "nil"
+			break
+		}
+		n.Children = append(n.Children, d.node("", v.Elem(), depth+1))
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := range v.NumField() {
+			f := t.Field(i)
+
+			var c *Node
+			if d.redact(f) {
+				c = &Node{
+					Name:     f.Name,
+					Type:     f.Type.String(),
+					Kind:     f.Type.Kind().String(),
+					Value:    Redacted,
+					Size:     f.Type.Size(),
+					Redacted: true,
+				}
+			} else {
+				c = d.node(f.Name, v.Field(i), depth+1)
+			}
+
+			c.Unexported = !f.IsExported()
+			c.Embedded = f.Anonymous
+			n.Children = append(n.Children, c)
+		}
+
+	case reflect.Array:
+		n.Len = ptr(v.Len())
+		d.elements(&n, v, depth)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			n.Nil = true
+		}
+		n.Len = ptr(v.Len())
+		n.Cap = ptr(v.Cap())
+		if v.Cap() > 0 {
+			n.Data = addr(v.UnsafePointer())
+		}
+		if v.Len() > 0 && d.covered(v) {
+			n.Seen = true
+			break
+		}
+		d.elements(&n, v, depth)
+
+	case reflect.Map:
+		if v.IsNil() {
+			n.Nil = true
+			break
+		}
+		n.Len = ptr(v.Len())
+		n.Data = addr(v.UnsafePointer())
+		if d.seen(v) {
+			n.Seen = true
+			break
+		}
+		d.entries(&n, v, depth)
+
+	case reflect.Chan:
+		if v.IsNil() {
+			n.Nil = true
+			break
+		}
+		n.Len = ptr(v.Len())
+		n.Cap = ptr(v.Cap())
+		n.Value = addr(v.UnsafePointer())
+
+	case reflect.Func, reflect.UnsafePointer:
+		if v.IsNil() {
+			n.Nil = true
+			break
+		}
+		n.Value = addr(v.UnsafePointer())
+	}
+
+	return &n
+}
+
+// covered reports whether a slice at least as long as this one, starting at
+// the same element, was already walked and records the slice as walked.
+func (d *dumper) covered(v reflect.Value) bool {
+	key := visit{v.Pointer(), v.Type()}
+	if d.walked[key] >= v.Len() {
+		return true
+	}
+	d.walked[key] = v.Len()
+	return false
+}
+
+// seen reports whether the entries of the map were already walked and marks
+// them as walked.
+func (d *dumper) seen(v reflect.Value) bool {
+	key := visit{v.Pointer(), v.Type()}
+	if d.visited[key] {
+		return true
+	}
+	d.visited[key] = true
+	return false
+}
+
+// elements adds a child for every element of an array or slice.
+func (d *dumper) elements(n *Node, v reflect.Value, depth int) {
+	count := d.limit(v.Len())
+	for i := range count {
+		n.Children = append(n.Children, d.node(fmt.Sprintf("[%d]", i), v.Index(i), depth+1))
+	}
+	n.More = v.Len() - count
+}
+
+// entries adds a child for every entry of a map, sorted by key so the
+// output is the same every time. Numbers are sorted by value and anything
+// else by its formatted key.
+func (d *dumper) entries(n *Node, v reflect.Value, depth int) {
+	type entry struct {
+		key   string
+		raw   reflect.Value
+		value reflect.Value
+	}
+
+	var list []entry
+	for iter := v.MapRange(); iter.Next(); {
+		list = append(list, entry{format(iter.Key()), iter.Key(), iter.Value()})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].raw, list[j].raw
+		switch {
+		case a.CanInt():
+			return a.Int() < b.Int()
+		case a.CanUint():
+			return a.Uint() < b.Uint()
+		case a.CanFloat():
+			return a.Float() < b.Float()
+		}
+		return list[i].key < list[j].key
+	})
+
+	count := d.limit(len(list))
+	for _, e := range list[:count] {
+		n.Children = append(n.Children, d.node("["+e.key+"]", e.value, depth+1))
+	}
+	n.More = len(list) - count
+}
+
+// limit returns how many of n elements to show.
+func (d *dumper) limit(n int) int {
+	if d.opts.MaxItems > 0 {
+		return min(n, d.opts.MaxItems)
+	}
+	return n
+}
+
+// redact reports whether the field's value must be hidden.
+func (d *dumper) redact(f reflect.StructField) bool {
+	if d.opts.RedactUnexported && !f.IsExported() {
+		return true
+	}
+	for _, name := range d.opts.RedactFields {
+		if strings.EqualFold(name, f.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// format returns a short form of a map key. Keys can be unexported values
+// so they are formatted without calling Interface.
+func format(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Pointer, reflect.Chan:
+		return addr(v.UnsafePointer())
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return format(v.Elem())
+	case reflect.Struct:
+		parts := make([]string, v.NumField())
+		for i := range parts {
+			parts[i] = format(v.Field(i))
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	case reflect.Array:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = format(v.Index(i))
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	}
+	return v.Type().String()
+}
+
+// addr formats a pointer as a hex address.
+func addr(p any) string {
+	return fmt.Sprintf("%p", p)
+}
+
+// ptr returns a pointer to a copy of n.
+func ptr(n int) *int {
+	return &n
+}
@@ -0,0 +1,163 @@
+package valuedump
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// find returns the first node below n, depth first, for which match is true.
+func find(n *Node, match func(*Node) bool) *Node {
+	if match(n) {
+		return n
+	}
+	for _, c := range n.Children {
+		if f := find(c, match); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestDump(t *testing.T) {
+	type user struct {
+		Name     string
+		password string
+		Tags     []string
+	}
+
+	u := user{Name: "Bill", password: "secret", Tags: []string{"admin"}}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "plain",
+			want: `valuedump.user (struct, 56 bytes)
+  Name string = "Bill" (string, 16 bytes, len 4, data *)
+  password string = "secret" (string, 16 bytes, len 6, data *, unexported)
+  Tags []string (slice, 24 bytes, len 1, cap 1, data *)
+    [0] string = "admin" (string, 16 bytes, len 5, data *)
+`,
+		},
+		{
+			name: "redacted",
+			opts: Options{RedactUnexported: true},
+			want: `valuedump.user (struct, 56 bytes)
+  Name string = "Bill" (string, 16 bytes, len 4, data *)
+  password string = <redacted> (string, 16 bytes, unexported)
+  Tags []string (slice, 24 bytes, len 1, cap 1, data *)
+    [0] string = "admin" (string, 16 bytes, len 5, data *)
+`,
+		},
+		{
+			name: "depth",
+			opts: Options{MaxDepth: 1},
+			want: `valuedump.user (struct, 56 bytes)
+  Name string = ... (string, 16 bytes)
+  password string = ... (string, 16 bytes, unexported)
+  Tags []string = ... (slice, 24 bytes)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := maskAddrs(Dump(u, tt.opts).String())
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDumpCycles checks that values holding themselves through a pointer,
+// a map or a slice are walked once and shown as seen the second time.
+func TestDumpCycles(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	p := &node{}
+	p.Next = p
+
+	m := map[string]any{}
+	m["self"] = m
+
+	s := []any{nil}
+	s[0] = s
+
+	mixed := map[string]any{}
+	mixed["list"] = []any{mixed}
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"pointer", p},
+		{"map", m},
+		{"slice", s},
+		{"map and slice", mixed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Dump(tt.v, Options{})
+
+			seen := find(n, func(n *Node) bool { return n.Seen })
+			if seen == nil {
+				t.Fatalf("no node marked as seen:\n%s", n)
+			}
+			if len(seen.Children) != 0 {
+				t.Errorf("seen node has %d children, want 0", len(seen.Children))
+			}
+			if !strings.Contains(n.String(), "shown above") {
+				t.Errorf("text doesn't refer back to the value:\n%s", n)
+			}
+		})
+	}
+}
+
+// TestDumpShared checks that a slice shared by two fields is only walked
+// once.
+func TestDumpShared(t *testing.T) {
+	data := []int{1, 2, 3}
+	v := struct{ A, B []int }{data, data}
+
+	n := Dump(v, Options{})
+	if a, b := n.Children[0], n.Children[1]; a.Seen || len(a.Children) != 3 || !b.Seen || len(b.Children) != 0 {
+		t.Errorf("A seen %v with %d children, B seen %v with %d children", a.Seen, len(a.Children), b.Seen, len(b.Children))
+	}
+}
+
+// TestDumpPrefix checks that a slice is only marked as seen when an earlier
+// slice of the same array covered all of its elements.
+func TestDumpPrefix(t *testing.T) {
+	data := []int{1, 2, 3}
+	v := struct{ A, B, C []int }{data[:1], data, data[:2]}
+
+	n := Dump(v, Options{})
+	got := make([]string, 0, len(n.Children))
+	for _, c := range n.Children {
+		got = append(got, fmt.Sprintf("%s %v %d", c.Name, c.Seen, len(c.Children)))
+	}
+	if want := "A false 1|B false 3|C true 0"; strings.Join(got, "|") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, "|"), want)
+	}
+}
+
+// maskAddrs replaces the addresses in the text with a star.
+func maskAddrs(text string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, "0x")
+		if i < 0 {
+			break
+		}
+		b.WriteString(text[:i] + "*")
+		text = strings.TrimLeft(text[i+2:], "0123456789abcdef")
+	}
+	b.WriteString(text)
+	return b.String()
+}